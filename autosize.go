@@ -0,0 +1,148 @@
+package lru
+
+import (
+	"context"
+	"runtime/metrics"
+	"time"
+)
+
+// heapObjectsMetric is the runtime metric of the memory occupied by the live and not yet swept heap objects.
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// AutoSizeConfig configures the AutoSizer. Zero fields take the default values.
+type AutoSizeConfig struct {
+	// MinCapacity is the lower bound of the capacity. Defaults to 1.
+	MinCapacity int
+	// MaxCapacity is the upper bound of the capacity. Defaults to 4 times the capacity of the cache
+	// at the creation of the sizer.
+	MaxCapacity int
+	// Interval is the period of the tuning by Run. Defaults to 10 seconds.
+	Interval time.Duration
+	// GrowThreshold is the share of the ghost hits among the lookups since the previous tuning,
+	// above which the capacity grows. Defaults to 0.05.
+	GrowThreshold float64
+	// ShrinkThreshold is the share of the ghost hits among the lookups since the previous tuning,
+	// below which the capacity shrinks. Zero disables shrinking on the low ghost hit rate.
+	ShrinkThreshold float64
+	// Step is the relative change of the capacity made by a single tuning. Defaults to 0.1.
+	Step float64
+	// MemoryBudget is the limit of the heap objects size in bytes. The capacity does not grow and shrinks
+	// while the heap exceeds it. Zero disables the limit.
+	MemoryBudget uint64
+}
+
+const (
+	defaultAutoSizeInterval = 10 * time.Second
+	defaultGrowThreshold    = 0.05
+	defaultAutoSizeStep     = 0.1
+)
+
+// Resizer is implemented by the caches which capacity can be tuned by the AutoSizer, such as the ones
+// created by NewCache.
+type Resizer interface {
+	StatsReporter
+	Resize(capacity int) int
+}
+
+// AutoSizer grows or shrinks the capacity of a cache within the configured bounds based on its ghost hit rate
+// and the memory budget. The cache should track the ghost entries, see WithGhostEntries, otherwise
+// the capacity never grows. Tune and Run must not be called concurrently.
+type AutoSizer struct {
+	cache     Resizer
+	cfg       AutoSizeConfig
+	heapBytes func() uint64
+	last      Stats
+}
+
+// NewAutoSizer returns a new AutoSizer of the cache with the given configuration.
+func NewAutoSizer(c Resizer, cfg AutoSizeConfig) *AutoSizer {
+	last := c.Stats()
+
+	if cfg.MinCapacity < 1 {
+		cfg.MinCapacity = 1
+	}
+	if cfg.MaxCapacity < cfg.MinCapacity {
+		cfg.MaxCapacity = max(cfg.MinCapacity, 4*last.Capacity)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultAutoSizeInterval
+	}
+	if cfg.GrowThreshold <= 0 {
+		cfg.GrowThreshold = defaultGrowThreshold
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = defaultAutoSizeStep
+	}
+
+	return &AutoSizer{
+		cache:     c,
+		cfg:       cfg,
+		heapBytes: readHeapBytes,
+		last:      last,
+	}
+}
+
+// Tune resizes the cache once based on the lookups since the previous tuning. Returns the new capacity.
+func (a *AutoSizer) Tune() int {
+	stats := a.cache.Stats()
+	prev := a.last
+	a.last = stats
+
+	capacity := stats.Capacity
+	step := max(1, int(float64(capacity)*a.cfg.Step))
+	overBudget := a.cfg.MemoryBudget > 0 && a.heapBytes() > a.cfg.MemoryBudget
+
+	var target int
+	lookups := (stats.Hits + stats.Misses) - (prev.Hits + prev.Misses)
+	ghostRate := 0.0
+	if lookups > 0 {
+		ghostRate = float64(stats.GhostHits-prev.GhostHits) / float64(lookups)
+	}
+
+	switch {
+	case overBudget:
+		target = capacity - step
+	case lookups == 0:
+		target = capacity
+	case ghostRate > a.cfg.GrowThreshold:
+		target = capacity + step
+	case ghostRate < a.cfg.ShrinkThreshold:
+		target = capacity - step
+	default:
+		target = capacity
+	}
+
+	target = min(max(target, a.cfg.MinCapacity), a.cfg.MaxCapacity)
+	if target != capacity {
+		a.cache.Resize(target)
+	}
+
+	return target
+}
+
+// Run tunes the cache every configured interval until the context is done.
+func (a *AutoSizer) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Tune()
+		}
+	}
+}
+
+// readHeapBytes returns the size of the heap objects reported by the runtime.
+func readHeapBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return sample[0].Value.Uint64()
+}
@@ -0,0 +1,106 @@
+package lru
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSizer(t *testing.T) {
+	// lookup makes a cache-aside lookup of every key.
+	lookup := func(c Cache[string, int], keys ...int) {
+		for _, k := range keys {
+			key := strconv.Itoa(k)
+			if _, ok := c.Get(key); !ok {
+				c.Set(key, k)
+			}
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		a := NewAutoSizer(NewCache[string, int](10).(*lruCache[string, int]), AutoSizeConfig{})
+		require.Equal(t, AutoSizeConfig{
+			MinCapacity:   1,
+			MaxCapacity:   40,
+			Interval:      defaultAutoSizeInterval,
+			GrowThreshold: defaultGrowThreshold,
+			Step:          defaultAutoSizeStep,
+		}, a.cfg)
+	})
+
+	t.Run("grows on ghost hits", func(t *testing.T) {
+		c := NewCache(10, WithGhostEntries[string, int](10)).(*lruCache[string, int])
+		a := NewAutoSizer(c, AutoSizeConfig{MaxCapacity: 12, Step: 0.1})
+
+		// The loop over 12 keys misses every time with the capacity of 10, the ghosts would have hit.
+		for range 3 {
+			lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		}
+		require.Equal(t, 11, a.Tune())
+
+		lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		require.Equal(t, 12, a.Tune())
+
+		lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		require.Equal(t, 12, a.Tune(), "the capacity is bounded")
+		require.Equal(t, 12, c.Stats().Capacity)
+	})
+
+	t.Run("keeps the capacity without lookups", func(t *testing.T) {
+		c := NewCache(10, WithGhostEntries[string, int](10)).(*lruCache[string, int])
+		a := NewAutoSizer(c, AutoSizeConfig{ShrinkThreshold: 0.01})
+		require.Equal(t, 10, a.Tune())
+	})
+
+	t.Run("shrinks on low ghost hit rate", func(t *testing.T) {
+		c := NewCache(10, WithGhostEntries[string, int](10)).(*lruCache[string, int])
+		a := NewAutoSizer(c, AutoSizeConfig{MinCapacity: 9, ShrinkThreshold: 0.01})
+
+		lookup(c, 0, 1, 0, 1)
+		require.Equal(t, 9, a.Tune())
+
+		lookup(c, 0, 1, 0, 1)
+		require.Equal(t, 9, a.Tune(), "the capacity is bounded")
+	})
+
+	t.Run("shrinks over the memory budget", func(t *testing.T) {
+		c := NewCache(10, WithGhostEntries[string, int](10)).(*lruCache[string, int])
+		a := NewAutoSizer(c, AutoSizeConfig{MemoryBudget: 1 << 20})
+		a.heapBytes = func() uint64 { return 2 << 20 }
+
+		for range 3 {
+			lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		}
+		require.Equal(t, 9, a.Tune())
+		require.Equal(t, 9, c.Stats().Len)
+
+		a.heapBytes = func() uint64 { return 1 << 10 }
+		lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		require.Equal(t, 10, a.Tune())
+	})
+
+	t.Run("heap metric", func(t *testing.T) {
+		require.NotZero(t, readHeapBytes())
+	})
+
+	t.Run("run", func(t *testing.T) {
+		c := NewCache(10, WithGhostEntries[string, int](10)).(*lruCache[string, int])
+		a := NewAutoSizer(c, AutoSizeConfig{Interval: time.Millisecond})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			a.Run(ctx)
+		}()
+
+		lookup(c, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+		require.Eventually(t, func() bool { return c.Stats().Capacity > 10 }, time.Second, time.Millisecond)
+
+		cancel()
+		<-done
+	})
+}
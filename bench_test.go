@@ -0,0 +1,227 @@
+package lru
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+const benchCapacity = 100_000
+
+// reportGC reports the GC cycles and the GC pause time per operation made since the previous memory statistics.
+func reportGC(b *testing.B, before *runtime.MemStats) {
+	b.Helper()
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+	b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+}
+
+// benchmarkEviction measures Set of new keys into a full cache, so every call evicts an item.
+func benchmarkEviction(b *testing.B, opts ...Option[int, int]) {
+	b.Helper()
+
+	c := NewCache(benchCapacity, opts...)
+	for i := range benchCapacity {
+		c.Set(i, i)
+	}
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		c.Set(benchCapacity+i, i)
+	}
+	b.StopTimer()
+
+	reportGC(b, &before)
+}
+
+func BenchmarkCache(b *testing.B) {
+	b.Run("get", func(b *testing.B) {
+		c := NewCache[int, int](benchCapacity)
+		for i := range benchCapacity {
+			c.Set(i, i)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			c.Get(i % benchCapacity)
+		}
+	})
+
+	b.Run("set new key", func(b *testing.B) {
+		c := NewCache[int, int](max(b.N, 1))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			c.Set(i, i)
+		}
+	})
+
+	b.Run("set update", func(b *testing.B) {
+		c := NewCache[int, int](benchCapacity)
+		for i := range benchCapacity {
+			c.Set(i, i)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			c.Set(i%benchCapacity, i)
+		}
+	})
+
+	b.Run("eviction", func(b *testing.B) { benchmarkEviction(b) })
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	caches := []struct {
+		name string
+		opts []Option[int, int]
+	}{
+		{"mutex", nil},
+		{"read buffers", []Option[int, int]{WithReadBuffers[int, int]()}},
+	}
+
+	for _, tc := range caches {
+		b.Run(tc.name, func(b *testing.B) {
+			c := NewCache(benchCapacity, tc.opts...)
+			for i := range benchCapacity {
+				c.Set(i, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					c.Get(i % benchCapacity)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// pointerListCache is the layout of the cache before the index-linked queue: a map of pointers
+// to the items of a pointer list. It is the baseline of BenchmarkListStorage.
+type pointerListCache struct {
+	queue List[cacheEntry[int, int]]
+	items map[int]*ListItem[cacheEntry[int, int]]
+}
+
+func newPointerListCache(capacity int) *pointerListCache {
+	c := &pointerListCache{
+		queue: NewList[cacheEntry[int, int]](),
+		items: make(map[int]*ListItem[cacheEntry[int, int]], capacity),
+	}
+	for i := range capacity {
+		c.items[i] = c.queue.PushFront(cacheEntry[int, int]{key: i, value: i})
+	}
+
+	return c
+}
+
+// benchmarkGC measures a full GC cycle with the live heap holding the full cache kept by keep.
+func benchmarkGC(b *testing.B, keep any) {
+	b.Helper()
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	b.ResetTimer()
+	for range b.N {
+		runtime.GC()
+	}
+	b.StopTimer()
+
+	reportGC(b, &before)
+	runtime.KeepAlive(keep)
+}
+
+func BenchmarkListStorage(b *testing.B) {
+	b.Run("eviction/index queue", func(b *testing.B) { benchmarkEviction(b) })
+	b.Run("eviction/preallocated index queue", func(b *testing.B) { benchmarkEviction(b, WithPreallocatedNodes[int, int]()) })
+
+	newCache := func(opts ...Option[int, int]) Cache[int, int] {
+		c := NewCache(benchCapacity, opts...)
+		for i := range benchCapacity {
+			c.Set(i, i)
+		}
+
+		return c
+	}
+
+	b.Run("gc/pointer list", func(b *testing.B) { benchmarkGC(b, newPointerListCache(benchCapacity)) })
+	b.Run("gc/index queue", func(b *testing.B) { benchmarkGC(b, newCache()) })
+	b.Run("gc/preallocated index queue", func(b *testing.B) { benchmarkGC(b, newCache(WithPreallocatedNodes[int, int]())) })
+}
+
+func BenchmarkListPushRemove(b *testing.B) {
+	lists := []struct {
+		name    string
+		newList func() List[string]
+	}{
+		{"pointer list", NewList[string]},
+		{"slice list", func() List[string] { return NewSliceList[string](benchCapacity) }},
+	}
+
+	for _, tc := range lists {
+		b.Run(tc.name, func(b *testing.B) {
+			l := tc.newList()
+			for i := range benchCapacity {
+				l.PushFront(strconv.Itoa(i))
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				l.Remove(l.Back())
+				l.PushFront("value")
+			}
+		})
+	}
+}
+
+func BenchmarkMissRatioTracking(b *testing.B) {
+	caches := []struct {
+		name string
+		opts []Option[int, int]
+	}{
+		{"disabled", nil},
+		{"1% sampling", []Option[int, int]{WithMissRatioTracking[int, int](0.01, 0)}},
+		{"10% sampling", []Option[int, int]{WithMissRatioTracking[int, int](0.1, 0)}},
+	}
+
+	for _, tc := range caches {
+		b.Run(tc.name, func(b *testing.B) {
+			c := NewCache(benchCapacity, tc.opts...)
+			for i := range benchCapacity {
+				c.Set(i, i)
+			}
+
+			// Random keys, so the sampled keys are reused at various distances.
+			rnd := rand.New(rand.NewPCG(1, 2))
+			keys := make([]int, benchCapacity)
+			for i := range keys {
+				keys[i] = rnd.IntN(benchCapacity)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				c.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
@@ -1,89 +1,339 @@
 package lru
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 // Cache is an interface for an LRU cache.
 type Cache[K comparable, V any] interface {
 	Set(key K, value V) bool
 	Get(key K) (V, bool)
+	GetMany(keys []K) map[K]V
+	GetManyOrLoad(ctx context.Context, keys []K, batchLoader BatchLoader[K, V]) (map[K]V, error)
+	GetOrSet(key K, value V) (V, bool)
+	SetIfAbsent(key K, value V) bool
+	Replace(key K, value V) bool
+	CompareAndSwapFunc(key K, oldValue, newValue V, equal func(a, b V) bool) bool
+	Compute(key K, fn func(old V, ok bool) (V, ComputeOp)) (V, bool)
+	Acquire(key K) (Handle[V], bool)
+	SetWithTags(key K, value V, tags ...string) bool
+	InvalidateTag(tag string) int
+	Delete(key K) bool
+	DeleteFunc(match func(key K, value V) bool) int
 	Clear()
+	Close() error
+	Len() int
+	Resize(capacity int) int
 }
 
 type lruCache[K comparable, V any] struct {
 	mu       sync.Mutex
 	capacity int
-	queue    List[*cacheListItem[K, V]]
-	items    map[K]*ListItem[*cacheListItem[K, V]]
+	queue    *entryQueue[K, V]
+	items    map[K]int32
+	// preallocate makes the queue allocate the nodes for the whole capacity at once.
+	preallocate bool
+	loading     map[K]*loadBatch[K, V]
+	tags        map[string]map[K]struct{}
+	onEvict     func(key K, value V, reason EvictionReason)
+	evicted     []evictedEntry[K, V]
+	// pinned is the number of the entries pinned by the handles, including the removed ones.
+	pinned int
+	reads  *readBuffers[K, V]
+	mrc    *missRatioTracker[K]
+	// sampledAccesses are recorded by the miss ratio tracker after the mutex is released.
+	sampledAccesses []sampledAccess[K]
+	stats           counters
+	// ghosts keeps the keys recently evicted due to the capacity, if they are tracked.
+	ghosts *ghostList[K]
+	// watch delivers the events to the subscribers of Watch. It is created by the first subscription.
+	watch    *watchHub[K, V]
+	watchCfg WatchConfig
+	events   []Event[K, V]
+	// closing keeps the values to be closed after the mutex is released.
+	closing      []V
+	closeValues  bool
+	onCloseError func(error)
 }
 
-type cacheListItem[K comparable, V any] struct {
+// cacheEntry is the payload of the queue nodes. It is stored in the node itself,
+// so cached entries do not take separate allocations and updates do not allocate at all.
+type cacheEntry[K comparable, V any] struct {
 	key   K
 	value V
+	tags  []string
+	// pin is set while the entry is pinned by the handles.
+	pin *pinState[V]
 }
 
-// NewCache returns a new Cache with the given capacity. If the capacity is less than 1, it returns nil.
-// The cache is implemented as a doubly-linked list with a map from keys to list items.
-func NewCache[K comparable, V any](capacity int) Cache[K, V] {
+// NewCache returns a new Cache with the given capacity and options. If the capacity is less than 1, it returns nil.
+// The cache is implemented as a doubly-linked queue of index-linked nodes with a map from keys to node indices.
+func NewCache[K comparable, V any](capacity int, opts ...Option[K, V]) Cache[K, V] {
 	if capacity < 1 {
 		return nil
 	}
 
-	return &lruCache[K, V]{
+	c := &lruCache[K, V]{
 		capacity: capacity,
-		queue:    NewList[*cacheListItem[K, V]](),
-		items:    make(map[K]*ListItem[*cacheListItem[K, V]], capacity),
+		items:    make(map[K]int32, capacity),
+		loading:  make(map[K]*loadBatch[K, V]),
+		tags:     make(map[string]map[K]struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	chunkSize := min(capacity, maxChunkSize)
+	if c.preallocate {
+		chunkSize = capacity
+	}
+	c.queue = newEntryQueue[K, V](chunkSize, c.preallocate)
+
+	return c
 }
 
 // Set adds a key-value pair to the cache. If the key already exists, it updates the value
 // and moves the item to the front of the queue. If the cache exceeds its capacity, it removes
 // the least recently used item. Returns true if the key was already present in the cache, false otherwise.
 func (c *lruCache[K, V]) Set(key K, value V) bool {
-	listItem := &cacheListItem[K, V]{key, value}
+	c.lock()
+	defer c.unlock()
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.set(key, value)
+}
+
+// set is a helper method for Set and other methods which store values in the cache.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) set(key K, value V) bool {
+	c.trackWrite(key)
+	c.abandonLoad(key)
 
 	// The element is present in the cache -> updating it's value, moving it to the front.
-	if v, ok := c.items[key]; ok {
-		v.Value = listItem
-		c.queue.MoveToFront(v)
+	if i, ok := c.items[key]; ok {
+		e := c.queue.entry(i)
+		old := e.value
+		e.value = value
+		c.replaced(e, old)
+		c.queue.moveToFront(i)
+		c.publish(key, value)
+		c.notify(Event[K, V]{Type: EventUpdate, Key: key, Value: value})
 		return true
 	}
 
-	newElem := c.queue.PushFront(listItem)
-	c.items[key] = newElem
-
-	// Removing the oldest cache item to sustain the capacity.
-	if c.queue.Len() > c.capacity {
-		delete(c.items, c.queue.Back().Value.key)
-		c.queue.Remove(c.queue.Back())
+	// Evicting the oldest cache item, its node is reused for the new one by the queue.
+	// The cache exceeds its capacity if all the items are pinned.
+	if c.queue.Len() >= c.capacity {
+		if i := c.victim(); i != nilNode {
+			c.remove(i, EvictionReasonCapacity)
+		}
 	}
 
+	c.items[key] = c.queue.pushFront(cacheEntry[K, V]{key: key, value: value})
+	c.publish(key, value)
+	c.unghost(key)
+	c.notify(Event[K, V]{Type: EventSet, Key: key, Value: value})
+
 	return false
 }
 
+// remove deletes the item of the node index from both the queue and the map for the given reason.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) remove(i int32, reason EvictionReason) {
+	item := c.queue.remove(i)
+	c.drop(&item, reason)
+}
+
+// drop deletes the item removed from the queue from the map and the tag index,
+// also schedules the eviction callback for it unless it is pinned. The caller must hold the mutex.
+func (c *lruCache[K, V]) drop(item *cacheEntry[K, V], reason EvictionReason) {
+	c.untag(item)
+	delete(c.items, item.key)
+	c.unpublish(item.key)
+	if reason == EvictionReasonCapacity {
+		c.stats.evictions.Add(1)
+		if c.ghosts != nil {
+			c.ghosts.add(item.key)
+		}
+	}
+	if reason == EvictionReasonDeleted {
+		c.abandonLoad(item.key)
+		c.notify(Event[K, V]{Type: EventDelete, Key: item.key, Value: item.value})
+	} else {
+		c.notify(Event[K, V]{Type: EventEvict, Key: item.key, Value: item.value, Reason: reason})
+	}
+	if !c.detach(item, reason) {
+		c.evict(item.key, item.value, reason)
+	}
+}
+
 // Get returns a value for a key if it exists in the cache, also moves the accessed item
 // to the front of the queue. Otherwise, returns zero value and false.
+// If the cache uses read buffers, the item is moved when the cache mutex is acquired next.
 func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	if c.reads != nil {
+		return c.getBuffered(key)
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	return c.get(key)
+}
+
+// get is a helper method for Get and other methods which read values from the cache.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	i, ok := c.items[key]
+
+	return c.finishLookup(func() K { return key }, i, ok)
+}
+
+// finishLookup does the bookkeeping of the lookup of the key which found the node index i if ok is true:
+// tracks the read for the miss ratio, counts the hit or the miss along with the ghost hit, and moves
+// the found item to the front of the queue. The key is requested only if the miss ratio or the ghost entries
+// are tracked, so the callers looking up a converted key convert it only when needed.
+// Returns the value of the found item. The caller must hold the mutex.
+func (c *lruCache[K, V]) finishLookup(key func() K, i int32, ok bool) (V, bool) {
 	var zeroVal V
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.mrc != nil {
+		c.trackRead(key())
+	}
+
+	c.recordLookup(key, ok)
+	if !ok {
+		return zeroVal, false
+	}
+
+	c.queue.moveToFront(i)
+
+	return c.queue.entry(i).value, true
+}
+
+// GetMany returns the values for all the keys which exist in the cache, also moves each accessed item
+// to the front of the queue in the order of keys. Missing keys are not present in the resulting map.
+func (c *lruCache[K, V]) GetMany(keys []K) map[K]V {
+	res := make(map[K]V, len(keys))
+
+	c.lock()
+	defer c.unlock()
+
+	for _, key := range keys {
+		if v, ok := c.get(key); ok {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+// Delete removes the key from the cache. Returns true if the key was present in the cache, false otherwise.
+func (c *lruCache[K, V]) Delete(key K) bool {
+	c.lock()
+	defer c.unlock()
+
+	c.abandonLoad(key)
 
-	if v, ok := c.items[key]; ok {
-		c.queue.MoveToFront(v)
-		return v.Value.value, true
+	i, ok := c.items[key]
+	if ok {
+		c.remove(i, EvictionReasonDeleted)
 	}
 
-	return zeroVal, false
+	return ok
+}
+
+// DeleteFunc removes all the items matching the predicate from the cache, walking the queue once.
+// The cache mutex is held during the match calls, so match must not call the cache methods.
+// Returns the number of removed items.
+func (c *lruCache[K, V]) DeleteFunc(match func(key K, value V) bool) int {
+	c.lock()
+	defer c.unlock()
+
+	n := 0
+	for i := c.queue.Front(); i != nilNode; {
+		next := c.queue.next(i)
+		if e := c.queue.entry(i); match(e.key, e.value) {
+			c.remove(i, EvictionReasonDeleted)
+			n++
+		}
+		i = next
+	}
+
+	return n
+}
+
+// Len returns the number of items in the cache. It may exceed the capacity while the entries are pinned.
+func (c *lruCache[K, V]) Len() int {
+	c.lock()
+	defer c.unlock()
+
+	return c.queue.Len()
+}
+
+// Resize sets the capacity of the cache, evicting the least recently used items exceeding it.
+// Capacities less than 1 are ignored. Returns the number of evicted items.
+func (c *lruCache[K, V]) Resize(capacity int) int {
+	if capacity < 1 {
+		return 0
+	}
+
+	c.lock()
+	defer c.unlock()
+
+	c.capacity = capacity
+
+	return c.trim()
+}
+
+// trim evicts the least recently used items which are not pinned while the cache exceeds its capacity.
+// Returns the number of evicted items. The caller must hold the mutex.
+func (c *lruCache[K, V]) trim() int {
+	n := 0
+
+	for c.queue.Len() > c.capacity {
+		victim := c.victim()
+		if victim == nilNode {
+			break
+		}
+		c.remove(victim, EvictionReasonCapacity)
+		n++
+	}
+
+	return n
 }
 
 // Clear removes all stored items from the cache.
 func (c *lruCache[K, V]) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lock()
+	defer c.unlock()
 
-	c.queue = NewList[*cacheListItem[K, V]]()
-	c.items = make(map[K]*ListItem[*cacheListItem[K, V]], c.capacity)
+	c.clear()
+}
+
+// clear is a helper method for Clear and Close. The caller must hold the mutex.
+func (c *lruCache[K, V]) clear() {
+	for key := range c.loading {
+		c.abandonLoad(key)
+	}
+
+	if c.onEvict != nil || c.pinned > 0 || c.closeValues {
+		for i := c.queue.Back(); i != nilNode; i = c.queue.prev(i) {
+			if e := c.queue.entry(i); !c.detach(e, EvictionReasonCleared) {
+				c.evict(e.key, e.value, EvictionReasonCleared)
+			}
+		}
+	}
+
+	c.queue.reset()
+	c.items = make(map[K]int32, c.capacity)
+	if c.reads != nil {
+		c.reads.index.Clear()
+	}
+	c.tags = make(map[string]map[K]struct{})
+	if c.ghosts != nil {
+		c.ghosts.clear()
+	}
+	c.notify(Event[K, V]{Type: EventClear, Reason: EvictionReasonCleared})
 }
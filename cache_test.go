@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/Averlex/lru/lrutest"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -65,9 +66,9 @@ func TestCache(t *testing.T) {
 	})
 
 	t.Run("incorrect capacity", incorrectCapacity)
-	t.Run("single element cache", cacheSingleItemSuite)
-	t.Run("multi element cache", cacheMultiItemSuite)
-	t.Run("eviction", cacheEvictionSuite)
+	t.Run("conformance", func(t *testing.T) {
+		lrutest.Run(t, func(capacity int) lrutest.Cache { return NewCache[string, int](capacity) })
+	})
 	t.Run("stress", cacheStressSuite)
 }
 
@@ -93,6 +94,21 @@ func TestCacheMultithreading(t *testing.T) {
 	wg.Wait()
 }
 
+func TestCacheLinearizability(t *testing.T) {
+	caches := map[string]lrutest.Factory{
+		"list": func(capacity int) lrutest.Cache { return NewCache[string, int](capacity) },
+		"preallocated nodes": func(capacity int) lrutest.Cache {
+			return NewCache(capacity, WithPreallocatedNodes[string, int]())
+		},
+	}
+
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			lrutest.RunLinearizability(t, newCache, lrutest.LinearizabilityConfig{Goroutines: 4, Operations: 100})
+		})
+	}
+}
+
 func notInCacheChecks(t *testing.T, c *Cache[string, any], key string) {
 	t.Helper()
 
@@ -132,217 +148,11 @@ func (s *CacheTestHelper) isInCache(k string, val any) {
 	s.Equal(val, v)
 }
 
-func (s *CacheTestHelper) setExisting(k string, val any) {
-	wasInCache := s.cache.Set(k, val)
-	s.True(wasInCache)
-}
-
 func (s *CacheTestHelper) setNew(k string, val any) {
 	wasInCache := s.cache.Set(k, val)
 	s.False(wasInCache)
 }
 
-type SingleItemCacheSuite struct {
-	CacheTestHelper
-}
-
-func (s *SingleItemCacheSuite) SetupTest() {
-	s.cache = NewCache[string, any](1)
-}
-
-func (s *SingleItemCacheSuite) TestSetToEmpty() {
-	s.setNew("key1", 100)
-}
-
-func (s *SingleItemCacheSuite) TestSetWithUpdate() {
-	s.setNew("key1", 100)
-	s.setExisting("key1", 200)
-}
-
-func (s *SingleItemCacheSuite) TestSetToFull() {
-	s.setNew("key1", 100)
-	s.setNew("key2", 200)
-}
-
-func (s *SingleItemCacheSuite) TestGetFromEmpty() {
-	s.isNotInCache("key1")
-}
-
-func (s *SingleItemCacheSuite) TestGetFromFilled() {
-	s.cache.Set("key1", 100)
-	s.isInCache("key1", 100)
-}
-
-func (s *SingleItemCacheSuite) TestGetNonExistent() {
-	s.cache.Set("key1", 100)
-	s.isNotInCache("key2")
-}
-
-func (s *SingleItemCacheSuite) TestClearEmpty() {
-	s.cache.Clear()
-	s.isNotInCache("key1")
-}
-
-func (s *SingleItemCacheSuite) TestClearFilled() {
-	s.cache.Set("key1", 100)
-	s.cache.Clear()
-	s.isNotInCache("key1")
-}
-
-func cacheSingleItemSuite(t *testing.T) {
-	t.Helper()
-	suite.Run(t, new(SingleItemCacheSuite))
-}
-
-type MultiItemCacheSuite struct {
-	CacheTestHelper
-}
-
-func (s *MultiItemCacheSuite) SetupTest() {
-	s.cache = NewCache[string, any](3)
-}
-
-func (s *MultiItemCacheSuite) TestSetToEmpty() {
-	s.setNew("key1", 100)
-	s.isInCache("key1", 100)
-}
-
-func (s *MultiItemCacheSuite) TestSetToPartiallyFilled() {
-	s.cache.Set("key1", 100)
-	s.setNew("key2", 200)
-	s.isInCache("key1", 100)
-	s.isInCache("key2", 200)
-}
-
-func (s *MultiItemCacheSuite) TestSetWithUpdate() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-
-	s.setExisting("key1", 101)
-	s.setExisting("key2", 201)
-
-	s.isInCache("key1", 101)
-	s.isInCache("key2", 201)
-}
-
-func (s *MultiItemCacheSuite) TestGetFromEmpty() {
-	s.isNotInCache("key1")
-}
-
-func (s *MultiItemCacheSuite) TestGetFromPartiallyFilled() {
-	s.cache.Set("key1", 100)
-	s.isInCache("key1", 100)
-	s.isNotInCache("key2")
-}
-
-func (s *MultiItemCacheSuite) TestGetFromFull() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-	s.cache.Set("key3", 300)
-
-	s.isInCache("key1", 100)
-	s.isInCache("key2", 200)
-	s.isInCache("key3", 300)
-}
-
-func (s *MultiItemCacheSuite) TestGetNonExistent() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-	s.cache.Set("key3", 300)
-	s.isNotInCache("key4")
-}
-
-func (s *MultiItemCacheSuite) TestClearEmptyCache() {
-	s.cache.Clear()
-	s.isNotInCache("key1")
-}
-
-func (s *MultiItemCacheSuite) TestClearPartiallyFilled() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-
-	s.cache.Clear()
-
-	s.isNotInCache("key1")
-	s.isNotInCache("key2")
-}
-
-func (s *MultiItemCacheSuite) TestClearFull() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-	s.cache.Set("key3", 300)
-
-	s.cache.Clear()
-
-	s.isNotInCache("key1")
-	s.isNotInCache("key2")
-	s.isNotInCache("key3")
-}
-
-func cacheMultiItemSuite(t *testing.T) {
-	t.Helper()
-	suite.Run(t, new(MultiItemCacheSuite))
-}
-
-type CacheEvictionSuite struct {
-	CacheTestHelper
-}
-
-func (s *CacheEvictionSuite) SetupTest() {
-	s.cache = NewCache[string, any](3)
-}
-
-func cacheEvictionSuite(t *testing.T) {
-	t.Helper()
-	suite.Run(t, new(CacheEvictionSuite))
-}
-
-func (s *CacheEvictionSuite) TestQueueSizeEviction() {
-	s.cache.Set("key1", 100)
-	s.cache.Set("key2", 200)
-	s.cache.Set("key3", 300)
-
-	s.setNew("key4", 400)
-
-	s.isNotInCache("key1")
-	s.isInCache("key2", 200)
-	s.isInCache("key3", 300)
-	s.isInCache("key4", 400)
-}
-
-func (s *CacheEvictionSuite) TestUnusedEviction() {
-	s.cache.Set("key1", 100) // [100 nil nil]
-	s.cache.Set("key2", 200) // [200 100 nil]
-	s.cache.Set("key3", 300) // [300 200 100]
-
-	s.cache.Get("key1") // [100 300 200]
-	s.cache.Get("key3") // [300 100 200]
-
-	s.cache.Set("key1", 101) // [101 300 200]
-	s.cache.Set("key2", 201) // [201 101 300]
-
-	s.cache.Set("key4", 400) // [400 201 101] -> key3 is evicted
-
-	s.isNotInCache("key3")
-	s.isInCache("key1", 101)
-	s.isInCache("key2", 201)
-	s.isInCache("key4", 400)
-}
-
-func (s *CacheEvictionSuite) TestSingleItemCacheEviction() {
-	c := NewCache[string, any](1)
-	c.Set("key1", 100)
-	c.Set("key2", 200)
-
-	v, ok := c.Get("key1")
-	s.False(ok)
-	s.Nil(v)
-
-	v, ok = c.Get("key2")
-	s.True(ok)
-	s.Equal(200, v)
-}
-
 type CacheStressSuite struct {
 	CacheTestHelper
 }
@@ -434,3 +244,38 @@ func cacheStressSuite(t *testing.T) {
 	t.Helper()
 	suite.Run(t, new(CacheStressSuite))
 }
+
+func TestNodeRecycling(t *testing.T) {
+	t.Run("steady state eviction does not allocate", func(t *testing.T) {
+		c := NewCache[int, int](100)
+		for i := range 100 {
+			c.Set(i, i)
+		}
+
+		i := 100
+		allocs := testing.AllocsPerRun(1000, func() {
+			c.Set(i, i)
+			i++
+		})
+		require.Zero(t, allocs)
+	})
+
+	t.Run("deleted items are reused", func(t *testing.T) {
+		c := NewCache[string, *int](3).(*lruCache[string, *int])
+		value := new(int)
+		c.Set("key1", value)
+		c.Set("key2", value)
+
+		i := c.items["key1"]
+		c.Delete("key1")
+		require.Equal(t, cacheEntry[string, *int]{}, *c.queue.entry(i), "the value is kept alive by the free node")
+
+		c.Set("key3", value)
+		require.Equal(t, i, c.items["key3"])
+		require.Equal(t, nilNode, c.queue.free)
+
+		v, ok := c.Get("key3")
+		require.True(t, ok)
+		require.Same(t, value, v)
+	})
+}
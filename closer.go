@@ -0,0 +1,107 @@
+package lru
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// WithValueClosing makes the cache close the values implementing io.Closer when they leave the cache:
+// on eviction, deletion, Clear and replacement by another value. The values are closed after the cache mutex
+// is released and the eviction callbacks are called. Values held by the handles of the pinned entries
+// are closed after the last release. The close errors are passed to onError if it is not nil.
+func WithValueClosing[K comparable, V any](onError func(error)) Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		c.closeValues = true
+		c.onCloseError = onError
+	}
+}
+
+// Close removes all stored items from the cache like Clear does. If the cache closes its values,
+// it returns the errors of closing them. Values of the pinned entries are closed on their last release.
+// The cache remains usable after Close.
+func (c *lruCache[K, V]) Close() error {
+	c.lock()
+	c.clear()
+	closing := c.closing
+	c.closing = nil
+	c.unlock()
+
+	return closeAll(closing)
+}
+
+// discard schedules closing of the value which left the cache. The caller must hold the mutex.
+func (c *lruCache[K, V]) discard(value V) {
+	if c.closeValues {
+		c.closing = append(c.closing, value)
+	}
+}
+
+// replaced schedules closing of the old value of the entry replaced by another one.
+// The old value of a pinned entry is kept until the last release, since it may be held by the handles.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) replaced(entry *cacheEntry[K, V], old V) {
+	if !c.closeValues || sameValue(old, entry.value) {
+		return
+	}
+
+	if entry.pin != nil {
+		entry.pin.stale = append(entry.pin.stale, old)
+		return
+	}
+
+	c.discard(old)
+}
+
+// closeScheduled closes the scheduled values and passes the errors to the error handler.
+// It is called after the mutex is released.
+func (c *lruCache[K, V]) closeScheduled(values []V) {
+	if err := closeAll(values); err != nil && c.onCloseError != nil {
+		c.onCloseError(err)
+	}
+}
+
+// closeAll closes all the values implementing io.Closer, returning their joined errors.
+func closeAll[V any](values []V) error {
+	var errs []error
+
+	for _, v := range values {
+		if closer, ok := any(v).(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sameValue reports whether the values are the same comparable value, e.g. the same pointer.
+// Values of incomparable types are never the same.
+func sameValue[V any](a, b V) bool {
+	x, y := any(a), any(b)
+
+	t := reflect.TypeOf(x)
+	if t == nil {
+		return y == nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return x == y
+	default:
+		return t.Comparable() && safeEqual(x, y)
+	}
+}
+
+// safeEqual compares the values of a comparable type. The comparison of the types like struct{ A any }
+// panics when the interface fields hold incomparable values, such values are not equal.
+func safeEqual(x, y any) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+
+	return x == y
+}
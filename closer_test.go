@@ -0,0 +1,157 @@
+package lru
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errClose = errors.New("close failed")
+
+// closeRecorder records the names of the closed resources.
+type closeRecorder struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (r *closeRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.closed...)
+}
+
+// resource is a value implementing io.Closer.
+type resource struct {
+	name    string
+	rec     *closeRecorder
+	failing bool
+}
+
+func (r *resource) Close() error {
+	r.rec.mu.Lock()
+	defer r.rec.mu.Unlock()
+
+	r.rec.closed = append(r.rec.closed, r.name)
+	if r.failing {
+		return errClose
+	}
+	return nil
+}
+
+func TestValueClosing(t *testing.T) {
+	t.Run("values are closed when they leave the cache", func(t *testing.T) {
+		rec := &closeRecorder{}
+		res := func(name string) *resource { return &resource{name: name, rec: rec} }
+		c := NewCache(2, WithValueClosing[string, *resource](nil))
+
+		c.Set("key1", res("v1"))
+		c.Set("key2", res("v2"))
+		c.Set("key3", res("v3")) // Evicts v1.
+		c.Set("key2", res("v2.1"))
+		c.Delete("key3")
+		require.Equal(t, []string{"v1", "v2", "v3"}, rec.names())
+
+		c.Set("key4", res("v4"))
+		c.Clear()
+		require.Equal(t, []string{"v1", "v2", "v3", "v2.1", "v4"}, rec.names())
+	})
+
+	t.Run("setting the same value does not close it", func(t *testing.T) {
+		rec := &closeRecorder{}
+		c := NewCache(1, WithValueClosing[string, *resource](nil))
+		v := &resource{name: "v1", rec: rec}
+
+		c.Set("key1", v)
+		c.Set("key1", v)
+		require.Empty(t, rec.names())
+	})
+
+	t.Run("values are closed after the eviction callback outside the mutex", func(t *testing.T) {
+		rec := &closeRecorder{}
+		var c Cache[string, *resource]
+		c = NewCache(1,
+			WithValueClosing[string, *resource](nil),
+			WithEvictionCallback(func(string, *resource, EvictionReason) {
+				require.Empty(t, rec.names())
+				c.Get("key2")
+			}),
+		)
+
+		c.Set("key1", &resource{name: "v1", rec: rec})
+		c.Set("key2", &resource{name: "v2", rec: rec})
+		require.Equal(t, []string{"v1"}, rec.names())
+	})
+
+	t.Run("values of pinned entries are closed on the last release", func(t *testing.T) {
+		rec := &closeRecorder{}
+		c := NewCache(1, WithValueClosing[string, *resource](nil))
+		c.Set("key1", &resource{name: "v1", rec: rec})
+
+		h, _ := c.Acquire("key1")
+		c.Set("key1", &resource{name: "v1.1", rec: rec})
+		c.Delete("key1")
+		require.Empty(t, rec.names())
+
+		h.Release()
+		require.Equal(t, []string{"v1", "v1.1"}, rec.names())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		rec := &closeRecorder{}
+		var errs []error
+		c := NewCache(1, WithValueClosing[string, *resource](func(err error) { errs = append(errs, err) }))
+
+		c.Set("key1", &resource{name: "v1", rec: rec, failing: true})
+		c.Set("key2", &resource{name: "v2", rec: rec})
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], errClose)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		rec := &closeRecorder{}
+		var errs []error
+		c := NewCache(2, WithValueClosing[string, *resource](func(err error) { errs = append(errs, err) }))
+		c.Set("key1", &resource{name: "v1", rec: rec, failing: true})
+		c.Set("key2", &resource{name: "v2", rec: rec})
+
+		require.ErrorIs(t, c.Close(), errClose)
+		require.ElementsMatch(t, []string{"v1", "v2"}, rec.names())
+		require.Empty(t, errs, "errors of Close are returned instead of being handled")
+
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("values which are not closers", func(t *testing.T) {
+		c := NewCache(1, WithValueClosing[string, []int](nil))
+		c.Set("key1", []int{1})
+		c.Set("key1", []int{2})
+		c.Set("key2", nil)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("values with incomparable interface fields", func(t *testing.T) {
+		type value struct{ A any }
+
+		c := NewCache(2, WithValueClosing[string, value](nil))
+		c.Set("key1", value{[]int{1}})
+		require.NotPanics(t, func() { c.Set("key1", value{[]int{2}}) })
+		c.Set("key1", value{1})
+		c.Set("key1", value{1})
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rec := &closeRecorder{}
+		c := NewCache[string, *resource](1)
+		c.Set("key1", &resource{name: "v1", rec: rec})
+		c.Set("key2", &resource{name: "v2", rec: rec})
+
+		require.NoError(t, c.Close())
+		require.Empty(t, rec.names())
+	})
+}
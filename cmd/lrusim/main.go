@@ -0,0 +1,98 @@
+// Command lrusim replays access traces through the caches of the lru package at a range of capacities
+// and reports their hit ratio, byte hit ratio and eviction counts.
+//
+// Usage:
+//
+//	lrusim -trace testdata/sample.txt -capacities 10,100,1000
+//	lrusim -trace testdata/sample.arc -format arc -policy all
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "lrusim:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the arguments, replays the trace and writes the report to out.
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("lrusim", flag.ContinueOnError)
+	tracePath := fs.String("trace", "", "path to the trace file (required)")
+	format := fs.String("format", formatPlain, "trace format: plain, csv, arc or lirs")
+	policy := fs.String("policy", "lru", "cache policy: "+strings.Join(policyNames(), ", ")+" or all")
+	capacities := fs.String("capacities", "100,1000,10000", "comma-separated cache capacities in items")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *tracePath == "" {
+		return errors.New("trace is required")
+	}
+
+	caps, err := parseCapacities(*capacities)
+	if err != nil {
+		return err
+	}
+
+	names := []string{*policy}
+	if *policy == "all" {
+		names = policyNames()
+	}
+
+	f, err := os.Open(*tracePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reqs, err := readTrace(f, *format)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "policy\tcapacity\trequests\thits\thit ratio\tbyte hit ratio\tevictions\t")
+
+	for _, name := range names {
+		for _, capacity := range caps {
+			res, err := simulate(name, capacity, reqs)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.4f\t%.4f\t%d\t\n",
+				res.policy, res.capacity, res.requests, res.hits, res.hitRatio(), res.byteHitRatio(), res.evictions)
+		}
+	}
+
+	return w.Flush()
+}
+
+// parseCapacities parses a comma-separated list of positive capacities.
+func parseCapacities(s string) ([]int, error) {
+	var caps []int
+
+	for _, field := range strings.Split(s, ",") {
+		capacity, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("parse capacity %q: %w", field, err)
+		}
+		if capacity < 1 {
+			return nil, fmt.Errorf("capacity must be positive, got %d", capacity)
+		}
+		caps = append(caps, capacity)
+	}
+
+	return caps, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("report", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := run([]string{"-trace", "testdata/sample.csv", "-format", "csv", "-policy", "all", "-capacities", "10, 100"}, out)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Contains(t, lines[0], "byte hit ratio")
+		require.Equal(t, []string{"lru", "10", "2000"}, strings.Fields(lines[1])[:3])
+		require.Equal(t, []string{"lru", "100", "2000"}, strings.Fields(lines[2])[:3])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			args   []string
+			errMsg string
+		}{
+			{"missing trace", nil, "trace is required"},
+			{"capacity", []string{"-trace", "testdata/sample.txt", "-capacities", "10,x"}, `parse capacity "x"`},
+			{"negative capacity", []string{"-trace", "testdata/sample.txt", "-capacities", "-1"}, "capacity must be positive"},
+			{"missing file", []string{"-trace", "testdata/missing.txt"}, "no such file"},
+			{"policy", []string{"-trace", "testdata/sample.txt", "-policy", "fifo"}, "unknown policy"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				err := run(tc.args, &bytes.Buffer{})
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Averlex/lru"
+)
+
+// policies are the cache implementations available to the simulator by their names.
+var policies = map[string]func(capacity int, opts ...lru.Option[string, struct{}]) lru.Cache[string, struct{}]{
+	"lru": lru.NewCache[string, struct{}],
+}
+
+// policyNames returns the sorted names of the available policies.
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// result is the outcome of replaying a trace through a cache.
+type result struct {
+	policy    string
+	capacity  int
+	requests  int
+	hits      int
+	bytes     int64
+	byteHits  int64
+	evictions int
+}
+
+// hitRatio returns the fraction of the requests served from the cache.
+func (r result) hitRatio() float64 {
+	if r.requests == 0 {
+		return 0
+	}
+	return float64(r.hits) / float64(r.requests)
+}
+
+// byteHitRatio returns the fraction of the requested bytes served from the cache.
+func (r result) byteHitRatio() float64 {
+	if r.bytes == 0 {
+		return 0
+	}
+	return float64(r.byteHits) / float64(r.bytes)
+}
+
+// simulate replays the requests through a new cache of the policy with the given capacity.
+// Missing keys are added to the cache after the miss.
+func simulate(policy string, capacity int, reqs []request) (result, error) {
+	newCache, ok := policies[policy]
+	if !ok {
+		return result{}, fmt.Errorf("unknown policy %q, available: %s", policy, strings.Join(policyNames(), ", "))
+	}
+
+	res := result{policy: policy, capacity: capacity, requests: len(reqs)}
+
+	c := newCache(capacity, lru.WithEvictionCallback(func(_ string, _ struct{}, reason lru.EvictionReason) {
+		if reason == lru.EvictionReasonCapacity {
+			res.evictions++
+		}
+	}))
+	if c == nil {
+		return result{}, fmt.Errorf("invalid capacity %d", capacity)
+	}
+
+	for _, req := range reqs {
+		res.bytes += req.size
+
+		if _, ok := c.Get(req.key); ok {
+			res.hits++
+			res.byteHits += req.size
+			continue
+		}
+
+		c.Set(req.key, struct{}{})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate(t *testing.T) {
+	reqs := []request{{"a", 10}, {"b", 20}, {"a", 10}, {"c", 30}, {"b", 20}, {"a", 10}}
+
+	t.Run("results", func(t *testing.T) {
+		for _, policy := range policyNames() {
+			res, err := simulate(policy, 2, reqs)
+			require.NoError(t, err)
+
+			// a hits once, then c evicts b, b evicts a and a evicts c.
+			require.Equal(t, result{
+				policy:    policy,
+				capacity:  2,
+				requests:  6,
+				hits:      1,
+				bytes:     100,
+				byteHits:  10,
+				evictions: 3,
+			}, res)
+			require.InDelta(t, 1.0/6, res.hitRatio(), 1e-9)
+			require.InDelta(t, 0.1, res.byteHitRatio(), 1e-9)
+		}
+	})
+
+	t.Run("empty trace", func(t *testing.T) {
+		res, err := simulate("lru", 1, nil)
+		require.NoError(t, err)
+		require.Zero(t, res.hitRatio())
+		require.Zero(t, res.byteHitRatio())
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		_, err := simulate("fifo", 1, reqs)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown policy "fifo"`)
+	})
+
+	t.Run("invalid capacity", func(t *testing.T) {
+		_, err := simulate("lru", 0, reqs)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid capacity")
+	})
+}
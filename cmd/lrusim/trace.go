@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// request is a single access of a trace.
+type request struct {
+	key  string
+	size int64
+}
+
+// Trace formats supported by the simulator.
+const (
+	// formatPlain is a key per line.
+	formatPlain = "plain"
+	// formatCSV is a key and its size in bytes per line, the header line is optional.
+	formatCSV = "csv"
+	// formatARC is the format of the ARC paper traces: the starting block, the number of blocks,
+	// an ignored field and the request number per line. Every block of the range is a separate access.
+	formatARC = "arc"
+	// formatLIRS is the format of the LIRS paper traces: a block number per line.
+	formatLIRS = "lirs"
+)
+
+// maxARCBlocks limits the number of blocks of a single ARC trace line, so a corrupted line cannot exhaust the memory.
+const maxARCBlocks = 1 << 20
+
+var errUnknownFormat = errors.New("unknown trace format")
+
+// readTrace reads all the requests of the trace in the given format.
+// Empty lines and lines starting with # are skipped in the line-based formats.
+func readTrace(r io.Reader, format string) ([]request, error) {
+	switch format {
+	case formatPlain:
+		return readLines(r, func(line string) ([]request, error) {
+			return []request{{key: line, size: 1}}, nil
+		})
+	case formatCSV:
+		return readCSV(r)
+	case formatARC:
+		return readLines(r, parseARC)
+	case formatLIRS:
+		return readLines(r, parseLIRS)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownFormat, format)
+	}
+}
+
+// readLines reads the trace line by line, parsing every non-empty line with parse.
+func readLines(r io.Reader, parse func(line string) ([]request, error)) ([]request, error) {
+	var reqs []request
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parsed, err := parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		reqs = append(reqs, parsed...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read trace: %w", err)
+	}
+
+	return reqs, nil
+}
+
+// parseARC parses a line of an ARC trace.
+func parseARC(line string) ([]request, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("expected at least 2 fields, got %d", len(fields))
+	}
+
+	start, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse starting block: %w", err)
+	}
+	count, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse number of blocks: %w", err)
+	}
+	if count > maxARCBlocks {
+		return nil, fmt.Errorf("number of blocks %d exceeds %d", count, maxARCBlocks)
+	}
+	if count > 0 && start > math.MaxUint64-(count-1) {
+		return nil, fmt.Errorf("block range %d+%d overflows", start, count)
+	}
+
+	reqs := make([]request, count)
+	for i := range reqs {
+		reqs[i] = request{key: strconv.FormatUint(start+uint64(i), 10), size: 1}
+	}
+
+	return reqs, nil
+}
+
+// parseLIRS parses a line of a LIRS trace.
+func parseLIRS(line string) ([]request, error) {
+	block, err := strconv.ParseUint(line, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse block: %w", err)
+	}
+
+	return []request{{key: strconv.FormatUint(block, 10), size: 1}}, nil
+}
+
+// readCSV reads a trace of key and size records. The first record is skipped if its size is not a number.
+func readCSV(r io.Reader) ([]request, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	var reqs []request
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return reqs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read trace: %w", err)
+		}
+
+		size, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			if n == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("record %d: parse size: %w", n, err)
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("record %d: negative size %d", n, size)
+		}
+
+		reqs = append(reqs, request{key: record[0], size: size})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTrace(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		reqs, err := readTrace(strings.NewReader("# comment\nkey1\n\n  key2 \nkey1\n"), formatPlain)
+		require.NoError(t, err)
+		require.Equal(t, []request{{"key1", 1}, {"key2", 1}, {"key1", 1}}, reqs)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		reqs, err := readTrace(strings.NewReader("key,size\nkey1,100\nkey2, 200\n"), formatCSV)
+		require.NoError(t, err)
+		require.Equal(t, []request{{"key1", 100}, {"key2", 200}}, reqs)
+
+		reqs, err = readTrace(strings.NewReader("key1,100\n"), formatCSV)
+		require.NoError(t, err)
+		require.Equal(t, []request{{"key1", 100}}, reqs)
+	})
+
+	t.Run("arc", func(t *testing.T) {
+		reqs, err := readTrace(strings.NewReader("10 3 0 0\n5 1 0 1\n"), formatARC)
+		require.NoError(t, err)
+		require.Equal(t, []request{{"10", 1}, {"11", 1}, {"12", 1}, {"5", 1}}, reqs)
+	})
+
+	t.Run("lirs", func(t *testing.T) {
+		reqs, err := readTrace(strings.NewReader("7\n3\n7\n"), formatLIRS)
+		require.NoError(t, err)
+		require.Equal(t, []request{{"7", 1}, {"3", 1}, {"7", 1}}, reqs)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			format string
+			trace  string
+			errMsg string
+		}{
+			{"unknown format", "bin", "", "unknown trace format"},
+			{"csv size", formatCSV, "key1,100\nkey2,big\n", "record 2: parse size"},
+			{"csv negative size", formatCSV, "key1,-1\n", "negative size"},
+			{"csv fields", formatCSV, "key1,100,extra\n", "wrong number of fields"},
+			{"arc fields", formatARC, "10\n", "line 1: expected at least 2 fields"},
+			{"arc block", formatARC, "1 1 0 0\nx 1 0 1\n", "line 2: parse starting block"},
+			{"arc too many blocks", formatARC, "0 18446744073709551615 0 0\n", "line 1: number of blocks"},
+			{"arc range overflow", formatARC, "18446744073709551615 2 0 0\n", "line 1: block range"},
+			{"lirs block", formatLIRS, "-1\n", "line 1: parse block"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := readTrace(strings.NewReader(tc.trace), tc.format)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("testdata", func(t *testing.T) {
+		files := map[string]string{
+			"testdata/sample.txt":  formatPlain,
+			"testdata/sample.csv":  formatCSV,
+			"testdata/sample.arc":  formatARC,
+			"testdata/sample.lirs": formatLIRS,
+		}
+
+		for path, format := range files {
+			f, err := os.Open(path)
+			require.NoError(t, err)
+
+			reqs, err := readTrace(f, format)
+			f.Close()
+			require.NoError(t, err, path)
+			require.NotEmpty(t, reqs, path)
+		}
+	})
+}
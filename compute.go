@@ -0,0 +1,111 @@
+package lru
+
+// ComputeOp is an action on the cache entry returned by the Compute callback.
+type ComputeOp int
+
+const (
+	// ComputeKeep leaves the entry as is. The recency of an existing entry is not updated.
+	ComputeKeep ComputeOp = iota
+	// ComputeSet stores the returned value and moves the entry to the front of the queue.
+	ComputeSet
+	// ComputeDelete removes the entry from the cache.
+	ComputeDelete
+)
+
+// GetOrSet returns the existing value for the key if it is present in the cache, also moves the accessed item
+// to the front of the queue. Otherwise, it stores the given value and returns it.
+// The returned boolean is true if the value was loaded, false if it was stored.
+func (c *lruCache[K, V]) GetOrSet(key K, value V) (V, bool) {
+	c.lock()
+	defer c.unlock()
+
+	if v, ok := c.get(key); ok {
+		return v, true
+	}
+
+	c.set(key, value)
+
+	return value, false
+}
+
+// SetIfAbsent stores the key-value pair only if the key is not present in the cache.
+// Returns true if the value was stored, false otherwise.
+func (c *lruCache[K, V]) SetIfAbsent(key K, value V) bool {
+	c.lock()
+	defer c.unlock()
+
+	if _, ok := c.items[key]; ok {
+		return false
+	}
+
+	c.set(key, value)
+
+	return true
+}
+
+// Replace updates the value only if the key is present in the cache, also moves the item
+// to the front of the queue. Returns true if the value was replaced, false otherwise.
+func (c *lruCache[K, V]) Replace(key K, value V) bool {
+	c.lock()
+	defer c.unlock()
+
+	if _, ok := c.items[key]; !ok {
+		return false
+	}
+
+	return c.set(key, value)
+}
+
+// CompareAndSwapFunc updates the value only if the key is present in the cache and its value is equal to oldValue
+// according to the equal function. On success it moves the item to the front of the queue.
+// Returns true if the value was swapped, false otherwise.
+func (c *lruCache[K, V]) CompareAndSwapFunc(key K, oldValue, newValue V, equal func(a, b V) bool) bool {
+	c.lock()
+	defer c.unlock()
+
+	i, ok := c.items[key]
+	if !ok || !equal(c.queue.entry(i).value, oldValue) {
+		return false
+	}
+
+	return c.set(key, newValue)
+}
+
+// Compute atomically updates the entry for the key. The function fn receives the current value and its presence
+// in the cache and returns a new value with an action to apply to the entry. The cache mutex is held
+// during the fn call, so it must not call the cache methods.
+// Returns the value stored for the key after the operation and its presence in the cache.
+func (c *lruCache[K, V]) Compute(key K, fn func(old V, ok bool) (V, ComputeOp)) (V, bool) {
+	var zeroVal V
+
+	c.lock()
+	defer c.unlock()
+
+	var old V
+	i, ok := c.items[key]
+	if ok {
+		old = c.queue.entry(i).value
+	}
+
+	value, op := fn(old, ok)
+
+	switch op {
+	case ComputeSet:
+		c.set(key, value)
+		return value, true
+	case ComputeDelete:
+		if ok {
+			c.remove(i, EvictionReasonDeleted)
+		}
+		return zeroVal, false
+	default:
+		return old, ok
+	}
+}
+
+// CompareAndSwap updates the value only if the key is present in the cache and its value is equal to oldValue.
+// It is a shorthand for the CompareAndSwapFunc method for comparable values.
+// Returns true if the value was swapped, false otherwise.
+func CompareAndSwap[K, V comparable](c Cache[K, V], key K, oldValue, newValue V) bool {
+	return c.CompareAndSwapFunc(key, oldValue, newValue, func(a, b V) bool { return a == b })
+}
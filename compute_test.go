@@ -0,0 +1,171 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicOperations(t *testing.T) {
+	t.Run("get or set", func(t *testing.T) {
+		c := NewCache[string, int](3)
+
+		v, loaded := c.GetOrSet("key1", 100)
+		require.False(t, loaded)
+		require.Equal(t, 100, v)
+
+		v, loaded = c.GetOrSet("key1", 200)
+		require.True(t, loaded)
+		require.Equal(t, 100, v)
+	})
+
+	t.Run("set if absent", func(t *testing.T) {
+		c := NewCache[string, int](3)
+
+		require.True(t, c.SetIfAbsent("key1", 100))
+		require.False(t, c.SetIfAbsent("key1", 200))
+
+		v, _ := c.Get("key1")
+		require.Equal(t, 100, v)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		c := NewCache[string, int](3)
+
+		require.False(t, c.Replace("key1", 100))
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+
+		c.Set("key1", 100)
+		require.True(t, c.Replace("key1", 200))
+		v, _ := c.Get("key1")
+		require.Equal(t, 200, v)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		c := NewCache[string, int](3)
+
+		require.False(t, CompareAndSwap(c, "key1", 0, 100))
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+
+		c.Set("key1", 100)
+		require.False(t, CompareAndSwap(c, "key1", 200, 300))
+		require.True(t, CompareAndSwap(c, "key1", 100, 300))
+
+		v, _ := c.Get("key1")
+		require.Equal(t, 300, v)
+	})
+
+	t.Run("compare and swap with custom equality", func(t *testing.T) {
+		c := NewCache[string, []int](3)
+		equal := func(a, b []int) bool { return len(a) == len(b) }
+
+		c.Set("key1", []int{1, 2})
+		require.False(t, c.CompareAndSwapFunc("key1", []int{1}, []int{3}, equal))
+		require.True(t, c.CompareAndSwapFunc("key1", []int{3, 4}, []int{5}, equal))
+
+		v, _ := c.Get("key1")
+		require.Equal(t, []int{5}, v)
+	})
+
+	t.Run("successful operations promote the item", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+
+		c.Replace("key1", 101)
+		c.Set("key3", 300)
+
+		_, ok := c.Get("key2")
+		require.False(t, ok)
+		_, ok = c.Get("key1")
+		require.True(t, ok)
+	})
+}
+
+func TestCompute(t *testing.T) {
+	increment := func(old int, ok bool) (int, ComputeOp) {
+		if !ok {
+			return 1, ComputeSet
+		}
+		return old + 1, ComputeSet
+	}
+
+	t.Run("set", func(t *testing.T) {
+		c := NewCache[string, int](3)
+
+		v, ok := c.Compute("key1", increment)
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+
+		v, ok = c.Compute("key1", increment)
+		require.True(t, ok)
+		require.Equal(t, 2, v)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		keep := func(int, bool) (int, ComputeOp) { return 0, ComputeKeep }
+
+		_, ok := c.Compute("key1", keep)
+		require.False(t, ok)
+		_, ok = c.Get("key1")
+		require.False(t, ok)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		v, ok := c.Compute("key1", keep)
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+
+		// Keeping an item does not promote it.
+		c.Set("key3", 300)
+		_, ok = c.Get("key1")
+		require.False(t, ok)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := NewCache[string, int](3)
+		del := func(int, bool) (int, ComputeOp) { return 0, ComputeDelete }
+
+		_, ok := c.Compute("key1", del)
+		require.False(t, ok)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		_, ok = c.Compute("key1", del)
+		require.False(t, ok)
+
+		_, ok = c.Get("key1")
+		require.False(t, ok)
+		v, ok := c.Get("key2")
+		require.True(t, ok)
+		require.Equal(t, 200, v)
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		const goroutines, iterations = 8, 10_000
+
+		c := NewCache[string, int](10)
+		wg := &sync.WaitGroup{}
+		wg.Add(goroutines)
+
+		for range goroutines {
+			go func() {
+				defer wg.Done()
+				for i := range iterations {
+					c.Compute("counter", increment)
+					c.SetIfAbsent(strconv.Itoa(i%5), i)
+				}
+			}()
+		}
+		wg.Wait()
+
+		v, ok := c.Get("counter")
+		require.True(t, ok)
+		require.Equal(t, goroutines*iterations, v)
+	})
+}
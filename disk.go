@@ -0,0 +1,186 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// diskDirPattern is the pattern of the subdirectory of the given directory owned by a disk store.
+	diskDirPattern = "lru-*"
+	// diskFileExt is the extension of the files created by the disk store.
+	diskFileExt = ".lru"
+)
+
+// Codec converts values to bytes and back for storing them outside of the memory.
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(data []byte) (V, error)
+}
+
+// GobCodec is a Codec based on encoding/gob.
+type GobCodec[V any] struct{}
+
+// Marshal encodes the value with encoding/gob.
+func (GobCodec[V]) Marshal(v V) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(&v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the value with encoding/gob.
+func (GobCodec[V]) Unmarshal(data []byte) (V, error) {
+	var v V
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+
+	return v, err
+}
+
+// diskStore is an LRU store keeping the values as files under a directory within a byte budget.
+// The index of the store is kept in the memory only. It is not safe for concurrent use.
+type diskStore[K comparable] struct {
+	dir      string
+	maxBytes int64
+	used     int64
+	seq      uint64
+	queue    List[*diskEntry[K]]
+	items    map[K]*ListItem[*diskEntry[K]]
+}
+
+type diskEntry[K comparable] struct {
+	key  K
+	path string
+	size int64
+}
+
+// newDiskStore returns a new disk store keeping its files in a new subdirectory of the directory with a unique name,
+// so the stores sharing the directory, including the ones of other processes, do not touch each other's files.
+// The directory is created if it does not exist. Other files of the directory are not touched.
+func newDiskStore[K comparable](dir string, maxBytes int64) (*diskStore[K], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(dir, diskDirPattern)
+	if err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	return &diskStore[K]{
+		dir:      dir,
+		maxBytes: maxBytes,
+		queue:    NewList[*diskEntry[K]](),
+		items:    make(map[K]*ListItem[*diskEntry[K]]),
+	}, nil
+}
+
+// put writes the data for the key to the disk, evicting the least recently used entries to fit
+// into the byte budget. Data exceeding the whole budget is not stored.
+func (s *diskStore[K]) put(key K, data []byte) error {
+	if err := s.remove(key); err != nil {
+		return err
+	}
+
+	size := int64(len(data))
+	if size > s.maxBytes {
+		return nil
+	}
+
+	s.seq++
+	path := filepath.Join(s.dir, fmt.Sprintf("%016x%s", s.seq, diskFileExt))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write cache file: %w", err)
+	}
+
+	s.items[key] = s.queue.PushFront(&diskEntry[K]{key: key, path: path, size: size})
+	s.used += size
+
+	// Removing the oldest entries to sustain the byte budget.
+	for s.used > s.maxBytes {
+		if err := s.remove(s.queue.Back().Value.key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// take reads the data for the key and removes the key from the store.
+// Returns false if the key is not present in the store.
+func (s *diskStore[K]) take(key K) ([]byte, bool, error) {
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	data, readErr := os.ReadFile(elem.Value.path)
+	if err := s.remove(key); err != nil {
+		return nil, false, err
+	}
+	if readErr != nil {
+		return nil, false, fmt.Errorf("read cache file: %w", readErr)
+	}
+
+	return data, true, nil
+}
+
+// remove deletes the key and its file from the store. Missing keys are ignored.
+func (s *diskStore[K]) remove(key K) error {
+	elem, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+
+	delete(s.items, key)
+	s.queue.Remove(elem)
+	s.used -= elem.Value.size
+
+	if err := os.Remove(elem.Value.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove cache file: %w", err)
+	}
+
+	return nil
+}
+
+// contains reports whether the key is present in the store.
+func (s *diskStore[K]) contains(key K) bool {
+	_, ok := s.items[key]
+	return ok
+}
+
+// clear removes all the keys and their files from the store.
+// Keeps on removing the files after an error, returning the first one.
+func (s *diskStore[K]) clear() error {
+	var firstErr error
+
+	for elem := s.queue.Front(); elem != nil; elem = elem.Next {
+		if err := os.Remove(elem.Value.path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = fmt.Errorf("remove cache file: %w", err)
+		}
+	}
+
+	s.used = 0
+	s.queue = NewList[*diskEntry[K]]()
+	s.items = make(map[K]*ListItem[*diskEntry[K]])
+
+	return firstErr
+}
+
+// close removes all the keys and the directory of the store. The store must not be used after that.
+func (s *diskStore[K]) close() error {
+	if err := s.clear(); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.dir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove cache directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package lru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGobCodec(t *testing.T) {
+	type value struct {
+		Name string
+		Tags []string
+	}
+
+	codec := GobCodec[value]{}
+	data, err := codec.Marshal(value{"name", []string{"a", "b"}})
+	require.NoError(t, err)
+
+	v, err := codec.Unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, value{"name", []string{"a", "b"}}, v)
+
+	_, err = codec.Unmarshal([]byte("garbage"))
+	require.Error(t, err)
+}
+
+func TestDiskStore(t *testing.T) {
+	t.Run("byte budget", func(t *testing.T) {
+		s, err := newDiskStore[string](t.TempDir(), 10)
+		require.NoError(t, err)
+
+		require.NoError(t, s.put("key1", []byte("1234")))
+		require.NoError(t, s.put("key2", []byte("1234")))
+		require.NoError(t, s.put("key3", []byte("1234"))) // key1 is evicted.
+		require.Equal(t, int64(8), s.used)
+		require.False(t, s.contains("key1"))
+
+		// Data exceeding the budget is not stored.
+		require.NoError(t, s.put("key4", []byte("12345678901")))
+		require.False(t, s.contains("key4"))
+
+		files, err := filepath.Glob(filepath.Join(s.dir, "*"+diskFileExt))
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+	})
+
+	t.Run("take", func(t *testing.T) {
+		s, err := newDiskStore[string](t.TempDir(), 10)
+		require.NoError(t, err)
+		require.NoError(t, s.put("key1", []byte("1234")))
+
+		data, ok, err := s.take("key1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []byte("1234"), data)
+		require.Equal(t, int64(0), s.used)
+
+		_, ok, err = s.take("key1")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("stores sharing the directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "cache")
+		s1, err := newDiskStore[string](dir, 10)
+		require.NoError(t, err)
+		require.NoError(t, s1.put("key1", []byte("1")))
+
+		s2, err := newDiskStore[string](dir, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, s1.dir, s2.dir)
+		require.NoError(t, s2.put("key2", []byte("2")))
+
+		data, ok, err := s1.take("key1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []byte("1"), data)
+	})
+
+	t.Run("files outside of the subdirectory are kept", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := filepath.Join(dir, "0000000000000001"+diskFileExt)
+		require.NoError(t, os.WriteFile(outside, []byte("data"), 0o644))
+
+		_, err := newDiskStore[string](dir, 10)
+		require.NoError(t, err)
+
+		require.FileExists(t, outside)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		s, err := newDiskStore[string](t.TempDir(), 10)
+		require.NoError(t, err)
+		require.NoError(t, s.put("key1", []byte("1234")))
+
+		require.NoError(t, s.clear())
+		require.False(t, s.contains("key1"))
+
+		entries, err := os.ReadDir(s.dir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		s, err := newDiskStore[string](t.TempDir(), 10)
+		require.NoError(t, err)
+		require.NoError(t, s.put("key1", []byte("1234")))
+
+		require.NoError(t, s.close())
+		require.NoDirExists(t, s.dir)
+	})
+}
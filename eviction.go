@@ -0,0 +1,80 @@
+package lru
+
+// EvictionReason describes why an item was removed from the cache.
+type EvictionReason int
+
+const (
+	// EvictionReasonCapacity means the item was the least recently used one when the cache exceeded its capacity.
+	EvictionReasonCapacity EvictionReason = iota
+	// EvictionReasonDeleted means the item was removed explicitly, e.g. by Delete, DeleteFunc or InvalidateTag.
+	EvictionReasonDeleted
+	// EvictionReasonCleared means the item was removed by Clear.
+	EvictionReasonCleared
+	// EvictionReasonMemoryPressure means the item was evicted by the MemoryWatcher when the heap approached its limit.
+	EvictionReasonMemoryPressure
+)
+
+// String returns the name of the reason.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonDeleted:
+		return "deleted"
+	case EvictionReasonCleared:
+		return "cleared"
+	case EvictionReasonMemoryPressure:
+		return "memory pressure"
+	default:
+		return "unknown"
+	}
+}
+
+// evictedEntry is an item removed from the cache waiting for the eviction callback call.
+type evictedEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// evict schedules the eviction callback call and closing of the value for the removed item.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) evict(key K, value V, reason EvictionReason) {
+	c.discard(value)
+
+	if c.onEvict == nil {
+		return
+	}
+
+	c.evicted = append(c.evicted, evictedEntry[K, V]{key, value, reason})
+}
+
+// unlock releases the mutex and delivers the events to the subscribers of Watch, then calls the eviction
+// callback for every item removed while it was held and closes the values which left the cache.
+// The events are delivered first, so the callbacks mutating the cache or panicking do not hold up the delivery.
+func (c *lruCache[K, V]) unlock() {
+	evicted, closing, events, sampled := c.evicted, c.closing, c.events, c.sampledAccesses
+	c.evicted, c.closing, c.events, c.sampledAccesses = nil, nil, nil, nil
+
+	var ticket uint64
+	if len(events) > 0 {
+		ticket = c.watch.ticket()
+	}
+	c.mu.Unlock()
+
+	for _, a := range sampled {
+		c.mrc.record(a.key, a.read)
+	}
+
+	if len(events) > 0 {
+		c.watch.deliver(ticket, events)
+	}
+
+	for _, e := range evicted {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+
+	if len(closing) > 0 {
+		c.closeScheduled(closing)
+	}
+}
@@ -0,0 +1,112 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type evictionRecord struct {
+	key    string
+	value  int
+	reason EvictionReason
+}
+
+// recordingCache returns a cache which records all the eviction callback calls to the returned slice.
+func recordingCache(capacity int, opts ...Option[string, int]) (*lruCache[string, int], *[]evictionRecord) {
+	records := &[]evictionRecord{}
+	opts = append(opts, WithEvictionCallback(func(key string, value int, reason EvictionReason) {
+		*records = append(*records, evictionRecord{key, value, reason})
+	}))
+	c := NewCache(capacity, opts...).(*lruCache[string, int])
+
+	return c, records
+}
+
+func TestEvictionCallback(t *testing.T) {
+	t.Run("capacity", func(t *testing.T) {
+		c, records := recordingCache(2)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Set("key1", 101)
+		require.Empty(t, *records)
+
+		c.Set("key3", 300)
+		require.Equal(t, []evictionRecord{{"key2", 200, EvictionReasonCapacity}}, *records)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		c, records := recordingCache(5)
+		c.Set("key1", 100)
+		c.SetWithTags("key2", 200, "tag")
+		c.Set("key3", 300)
+
+		c.Delete("key1")
+		c.Delete("key1")
+		c.InvalidateTag("tag")
+		c.Compute("key3", func(int, bool) (int, ComputeOp) { return 0, ComputeDelete })
+
+		require.Equal(t, []evictionRecord{
+			{"key1", 100, EvictionReasonDeleted},
+			{"key2", 200, EvictionReasonDeleted},
+			{"key3", 300, EvictionReasonDeleted},
+		}, *records)
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		c, records := recordingCache(5)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Clear()
+
+		require.Equal(t, []evictionRecord{
+			{"key1", 100, EvictionReasonCleared},
+			{"key2", 200, EvictionReasonCleared},
+		}, *records)
+	})
+
+	t.Run("callback may call the cache", func(t *testing.T) {
+		var c Cache[string, int]
+		var evicted []string
+		c = NewCache(1, WithEvictionCallback(func(key string, _ int, _ EvictionReason) {
+			if _, ok := c.Get(key); !ok {
+				evicted = append(evicted, key)
+			}
+		}))
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		require.Equal(t, []string{"key1"}, evicted)
+	})
+
+	t.Run("reason names", func(t *testing.T) {
+		require.Equal(t, "capacity", EvictionReasonCapacity.String())
+		require.Equal(t, "deleted", EvictionReasonDeleted.String())
+		require.Equal(t, "cleared", EvictionReasonCleared.String())
+		require.Equal(t, "memory pressure", EvictionReasonMemoryPressure.String())
+		require.Equal(t, "unknown", EvictionReason(-1).String())
+	})
+}
+
+func TestDeleteFunc(t *testing.T) {
+	c, records := recordingCache(5)
+	for i, key := range []string{"tenant1:a", "tenant2:a", "tenant1:b", "tenant1:c"} {
+		c.SetWithTags(key, i, "tag")
+	}
+
+	n := c.DeleteFunc(func(key string, value int) bool {
+		return key[:7] == "tenant1" && value > 0
+	})
+	require.Equal(t, 2, n)
+	require.Equal(t, []evictionRecord{
+		{"tenant1:c", 3, EvictionReasonDeleted},
+		{"tenant1:b", 2, EvictionReasonDeleted},
+	}, *records)
+
+	require.Equal(t, map[string]int{"tenant1:a": 0, "tenant2:a": 1},
+		c.GetMany([]string{"tenant1:a", "tenant2:a", "tenant1:b", "tenant1:c"}))
+
+	// The tag index is kept consistent.
+	require.Equal(t, 2, c.InvalidateTag("tag"))
+	require.Equal(t, 0, c.DeleteFunc(func(string, int) bool { return true }))
+}
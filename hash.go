@@ -0,0 +1,184 @@
+package lru
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// Hasher returns the hash of the key. Equal keys must have equal hashes.
+type Hasher[K any] func(key K) uint64
+
+// Equal reports whether the keys are equal.
+type Equal[K any] func(a, b K) bool
+
+// bytesSeed is the seed of HashBytes. It is random for every process.
+var bytesSeed = maphash.MakeSeed()
+
+// HashBytes is a Hasher for []byte keys. Use it with bytes.Equal.
+func HashBytes(key []byte) uint64 {
+	return maphash.Bytes(bytesSeed, key)
+}
+
+// HashCache is an LRU cache for the keys which are not comparable, e.g. []byte or structs with slices.
+// The keys are indexed by their hashes and compared with the Equal function on collisions.
+// The keys must not be modified after they are stored in the cache. It is safe for concurrent use.
+type HashCache[K, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	hash     Hasher[K]
+	equal    Equal[K]
+	queue    List[hashEntry[K, V]]
+	buckets  map[uint64][]*ListItem[hashEntry[K, V]]
+}
+
+type hashEntry[K, V any] struct {
+	hash  uint64
+	key   K
+	value V
+}
+
+// NewHashCache returns a new HashCache with the given capacity, hash and equality functions.
+// If the capacity is less than 1, it returns nil.
+func NewHashCache[K, V any](capacity int, hash Hasher[K], equal Equal[K]) *HashCache[K, V] {
+	if capacity < 1 {
+		return nil
+	}
+
+	return &HashCache[K, V]{
+		capacity: capacity,
+		hash:     hash,
+		equal:    equal,
+		queue:    NewList[hashEntry[K, V]](),
+		buckets:  make(map[uint64][]*ListItem[hashEntry[K, V]], capacity),
+	}
+}
+
+// Set adds a key-value pair to the cache. If the key already exists, it updates the value
+// and moves the item to the front of the queue. If the cache exceeds its capacity, it removes
+// the least recently used item. Returns true if the key was already present in the cache, false otherwise.
+func (c *HashCache[K, V]) Set(key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.hash(key)
+	if elem := c.find(h, key); elem != nil {
+		elem.Value.value = value
+		c.queue.MoveToFront(elem)
+		return true
+	}
+
+	if c.queue.Len() >= c.capacity {
+		c.remove(c.queue.Back())
+	}
+
+	c.buckets[h] = append(c.buckets[h], c.queue.PushFront(hashEntry[K, V]{hash: h, key: key, value: value}))
+
+	return false
+}
+
+// Get returns a value for a key if it exists in the cache, also moves the accessed item
+// to the front of the queue. Otherwise, returns zero value and false.
+func (c *HashCache[K, V]) Get(key K) (V, bool) {
+	var zeroVal V
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem := c.find(c.hash(key), key)
+	if elem == nil {
+		return zeroVal, false
+	}
+
+	c.queue.MoveToFront(elem)
+
+	return elem.Value.value, true
+}
+
+// Delete removes the key from the cache. Returns true if the key was present in the cache, false otherwise.
+func (c *HashCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem := c.find(c.hash(key), key)
+	if elem == nil {
+		return false
+	}
+
+	c.remove(elem)
+
+	return true
+}
+
+// Len returns the number of items in the cache.
+func (c *HashCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.queue.Len()
+}
+
+// Clear removes all stored items from the cache.
+func (c *HashCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queue = NewList[hashEntry[K, V]]()
+	c.buckets = make(map[uint64][]*ListItem[hashEntry[K, V]], c.capacity)
+}
+
+// find returns the queue item of the key, or nil if the key is not present. The caller must hold the mutex.
+func (c *HashCache[K, V]) find(h uint64, key K) *ListItem[hashEntry[K, V]] {
+	for _, elem := range c.buckets[h] {
+		if c.equal(elem.Value.key, key) {
+			return elem
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the item from both the queue and its bucket. The caller must hold the mutex.
+func (c *HashCache[K, V]) remove(elem *ListItem[hashEntry[K, V]]) {
+	h := elem.Value.hash
+	bucket := c.buckets[h]
+
+	for i, e := range bucket {
+		if e != elem {
+			continue
+		}
+
+		last := len(bucket) - 1
+		bucket[i] = bucket[last]
+		bucket[last] = nil
+		bucket = bucket[:last]
+		break
+	}
+
+	if len(bucket) == 0 {
+		delete(c.buckets, h)
+	} else {
+		c.buckets[h] = bucket
+	}
+
+	c.queue.Remove(elem)
+}
+
+// GetBytes is Get for a string-keyed cache taking the key as a byte slice.
+// It does not allocate for the caches created by NewCache unless they track the miss ratio
+// or check the missing keys against the ghost entries, other implementations get a converted key.
+// Read-buffered caches look the key up under the cache mutex.
+func GetBytes[V any](c Cache[string, V], key []byte) (V, bool) {
+	lc, ok := c.(*lruCache[string, V])
+	if !ok {
+		return c.Get(string(key))
+	}
+
+	lc.lock()
+	defer lc.unlock()
+
+	// The conversion in the map index expression does not allocate, the other ones are made
+	// by finishLookup only if the key is tracked.
+	i, ok := lc.items[string(key)]
+
+	return lc.finishLookup(func() string { return string(key) }, i, ok)
+}
@@ -0,0 +1,147 @@
+package lru
+
+import (
+	"bytes"
+	"hash/maphash"
+	"testing"
+
+	"github.com/Averlex/lru/lrutest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashCache(t *testing.T) {
+	t.Run("conformance", func(t *testing.T) {
+		seed := maphash.MakeSeed()
+		lrutest.Run(t, func(capacity int) lrutest.Cache {
+			return NewHashCache[string, int](
+				capacity,
+				func(key string) uint64 { return maphash.String(seed, key) },
+				func(a, b string) bool { return a == b },
+			)
+		})
+	})
+
+	t.Run("incorrect capacity", func(t *testing.T) {
+		require.Nil(t, NewHashCache[[]byte, int](0, HashBytes, bytes.Equal))
+	})
+
+	t.Run("byte slice keys", func(t *testing.T) {
+		c := NewHashCache[[]byte, int](2, HashBytes, bytes.Equal)
+
+		require.False(t, c.Set([]byte("key1"), 100))
+		require.True(t, c.Set([]byte("key1"), 101))
+		require.False(t, c.Set([]byte("key2"), 200))
+
+		v, ok := c.Get([]byte("key1"))
+		require.True(t, ok)
+		require.Equal(t, 101, v)
+
+		c.Set([]byte("key3"), 300) // key2 is the least recently used one.
+		_, ok = c.Get([]byte("key2"))
+		require.False(t, ok)
+		require.Equal(t, 2, c.Len())
+
+		require.True(t, c.Delete([]byte("key1")))
+		require.False(t, c.Delete([]byte("key1")))
+		require.Equal(t, 1, c.Len())
+
+		c.Clear()
+		require.Zero(t, c.Len())
+		_, ok = c.Get([]byte("key3"))
+		require.False(t, ok)
+	})
+
+	t.Run("hash collisions", func(t *testing.T) {
+		type key struct {
+			id   int
+			path []string
+		}
+		c := NewHashCache[key, int](
+			2,
+			func(key) uint64 { return 0 },
+			func(a, b key) bool { return a.id == b.id },
+		)
+
+		c.Set(key{id: 1}, 100)
+		c.Set(key{id: 2}, 200)
+		c.Set(key{id: 3}, 300)
+
+		_, ok := c.Get(key{id: 1})
+		require.False(t, ok)
+		v, ok := c.Get(key{id: 2, path: []string{"ignored"}})
+		require.True(t, ok)
+		require.Equal(t, 200, v)
+
+		require.True(t, c.Delete(key{id: 3}))
+		v, ok = c.Get(key{id: 2})
+		require.True(t, ok)
+		require.Equal(t, 200, v)
+	})
+}
+
+func TestGetBytes(t *testing.T) {
+	t.Run("cache", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+
+		key := []byte("key1")
+		v, ok := GetBytes(c, key)
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+
+		c.Set("key3", 300) // key1 is promoted by GetBytes, so key2 is evicted.
+		_, ok = GetBytes(c, []byte("key2"))
+		require.False(t, ok)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			GetBytes(c, key)
+		})
+		require.Zero(t, allocs)
+	})
+
+	t.Run("lookups are counted like Get", func(t *testing.T) {
+		newCache := func() *lruCache[string, int] {
+			return NewCache(2, WithGhostEntries[string, int](2), WithMissRatioTracking[string, int](1, 0)).(*lruCache[string, int])
+		}
+		byString, byBytes := newCache(), newCache()
+
+		for _, key := range []string{"key1", "key2", "key1", "key3", "key2", "key4", "key1"} {
+			want, wantOK := byString.Get(key)
+			got, gotOK := GetBytes(byBytes, []byte(key))
+			require.Equal(t, wantOK, gotOK, key)
+			require.Equal(t, want, got, key)
+			if !wantOK {
+				byString.Set(key, 0)
+				byBytes.Set(key, 0)
+			}
+		}
+
+		require.Equal(t, byString.Stats(), byBytes.Stats())
+		want, _ := byString.EstimateMissRatios(0.5, 1, 2)
+		got, ok := byBytes.EstimateMissRatios(0.5, 1, 2)
+		require.True(t, ok)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("other implementations", func(t *testing.T) {
+		c := &recordingGetter{Cache: NewCache[string, int](1)}
+		c.Set("key1", 100)
+
+		v, ok := GetBytes[int](c, []byte("key1"))
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+		require.Equal(t, []string{"key1"}, c.keys)
+	})
+}
+
+// recordingGetter is a Cache wrapper recording the keys passed to Get.
+type recordingGetter struct {
+	Cache[string, int]
+	keys []string
+}
+
+func (r *recordingGetter) Get(key string) (int, bool) {
+	r.keys = append(r.keys, key)
+	return r.Cache.Get(key)
+}
@@ -0,0 +1,545 @@
+package lru
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrInvalidationsLost is reported by the InvalidationBus when the invalidations of a peer are lost in transit.
+var ErrInvalidationsLost = errors.New("invalidations lost")
+
+// errMalformedMessage is reported by the InvalidationBus for the messages it cannot decode.
+var errMalformedMessage = errors.New("malformed invalidation message")
+
+// InvalidationConfig configures the InvalidationBus.
+type InvalidationConfig[K comparable] struct {
+	// Network is either "udp" or "tcp".
+	Network string
+	// Addr is the local address the bus listens on, e.g. "127.0.0.1:0".
+	Addr string
+	// Peers are the addresses of the other buses the invalidations are sent to. More can be added with AddPeer.
+	Peers []string
+	// Codec converts the keys to bytes and back. GobCodec is used if it is nil.
+	Codec Codec[K]
+	// WriteTimeout limits the time of sending a message to a peer. Defaults to 1 second.
+	WriteTimeout time.Duration
+	// ClearOnLoss clears the local cache when the invalidations of a peer are lost,
+	// since some of the keys may be stale then.
+	ClearOnLoss bool
+	// OnError is called for the network and decoding errors, and for the lost invalidations
+	// with an error wrapping ErrInvalidationsLost. Optional.
+	OnError func(error)
+}
+
+const (
+	defaultWriteTimeout = time.Second
+
+	invalidationMagic   = 'L'
+	invalidationVersion = 1
+	// invalidationHeaderSize is the size of the magic, version, operation, origin, sequence number and payload size.
+	invalidationHeaderSize = 1 + 1 + 1 + 8 + 8 + 4
+	// maxInvalidationPayload keeps the messages within a single UDP datagram.
+	maxInvalidationPayload = 60 << 10
+
+	opDeleteKey byte = 1
+	opDeleteTag byte = 2
+)
+
+// invalidation is a message of the InvalidationBus.
+type invalidation struct {
+	op      byte
+	origin  uint64
+	seq     uint64
+	payload []byte
+}
+
+// marshal encodes the message: the magic, version, operation, origin, sequence number,
+// payload size and payload, the numbers are big endian.
+func (m invalidation) marshal() []byte {
+	buf := make([]byte, invalidationHeaderSize, invalidationHeaderSize+len(m.payload))
+	buf[0], buf[1], buf[2] = invalidationMagic, invalidationVersion, m.op
+	binary.BigEndian.PutUint64(buf[3:], m.origin)
+	binary.BigEndian.PutUint64(buf[11:], m.seq)
+	binary.BigEndian.PutUint32(buf[19:], uint32(len(m.payload)))
+
+	return append(buf, m.payload...)
+}
+
+// unmarshalInvalidation decodes a message encoded by marshal.
+func unmarshalInvalidation(data []byte) (invalidation, error) {
+	if len(data) < invalidationHeaderSize || data[0] != invalidationMagic || data[1] != invalidationVersion {
+		return invalidation{}, errMalformedMessage
+	}
+
+	m := invalidation{
+		op:     data[2],
+		origin: binary.BigEndian.Uint64(data[3:]),
+		seq:    binary.BigEndian.Uint64(data[11:]),
+	}
+	if m.op != opDeleteKey && m.op != opDeleteTag {
+		return invalidation{}, errMalformedMessage
+	}
+
+	size := binary.BigEndian.Uint32(data[19:])
+	if int(size) != len(data)-invalidationHeaderSize {
+		return invalidation{}, errMalformedMessage
+	}
+	m.payload = data[invalidationHeaderSize:]
+
+	return m, nil
+}
+
+// seqWindow tracks the sequence numbers received from a peer. It keeps the highest one and a bitmap
+// of the 64 preceding it, so duplicates are detected and reordering within the window is not a loss.
+type seqWindow struct {
+	highest uint64
+	// seen has the bit i set if highest-i was received.
+	seen uint64
+}
+
+// newSeqWindow returns a window starting at the first received sequence number.
+// The numbers preceding it are treated as received, since they were sent before the peer was known.
+func newSeqWindow(seq uint64) *seqWindow {
+	return &seqWindow{highest: seq, seen: ^uint64(0)}
+}
+
+// receive records the sequence number. Returns false for a duplicate,
+// and the number of the sequence numbers which left the window without being received.
+func (w *seqWindow) receive(seq uint64) (bool, uint64) {
+	if seq <= w.highest {
+		i := w.highest - seq
+		if i >= 64 {
+			// Too old to be tracked, it has been reported as lost already.
+			return true, 0
+		}
+		if w.seen&(1<<i) != 0 {
+			return false, 0
+		}
+		w.seen |= 1 << i
+		return true, 0
+	}
+
+	shift := seq - w.highest
+	var lost uint64
+	if shift >= 64 {
+		lost = uint64(64-bits.OnesCount64(w.seen)) + shift - 64
+		w.seen = 0
+	} else {
+		leaving := w.seen >> (64 - shift)
+		lost = shift - uint64(bits.OnesCount64(leaving))
+		w.seen <<= shift
+	}
+	w.highest = seq
+	w.seen |= 1
+
+	return true, lost
+}
+
+// peer is a destination of the invalidations.
+type peer struct {
+	addr string
+	udp  net.Addr
+	tcp  net.Conn
+}
+
+// InvalidationBus keeps the caches of several processes consistent. The keys changed through the bus
+// are invalidated in the caches of its peers: deletes and tag invalidations are applied as they are,
+// updates delete the stale key. It is safe for concurrent use.
+//
+// Every bus has a random origin and numbers its messages. The messages of its own origin are ignored,
+// and the invalidations received from the peers are applied to the local cache without being sent further,
+// so there are no loops. Duplicates are ignored, gaps in the sequence numbers are reported as lost invalidations.
+// The messages are sent synchronously. Over TCP a failed connection is dialed again on the next message.
+type InvalidationBus[K comparable, V any] struct {
+	cache  Cache[K, V]
+	cfg    InvalidationConfig[K]
+	origin uint64
+
+	packets  net.PacketConn
+	listener net.Listener
+
+	sendMu sync.Mutex
+	seq    uint64
+	peers  []*peer
+
+	mu      sync.Mutex
+	windows map[uint64]*seqWindow
+	conns   map[net.Conn]struct{}
+	closed  bool
+
+	wg sync.WaitGroup
+}
+
+// NewInvalidationBus returns a new InvalidationBus for the cache listening on the configured address.
+// The bus must be closed with the Close method.
+func NewInvalidationBus[K comparable, V any](c Cache[K, V], cfg InvalidationConfig[K]) (*InvalidationBus[K, V], error) {
+	if cfg.Codec == nil {
+		cfg.Codec = GobCodec[K]{}
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+
+	b := &InvalidationBus[K, V]{
+		cache:   c,
+		cfg:     cfg,
+		origin:  rand.Uint64(),
+		windows: make(map[uint64]*seqWindow),
+		conns:   make(map[net.Conn]struct{}),
+	}
+
+	switch cfg.Network {
+	case "udp", "udp4", "udp6":
+		conn, err := net.ListenPacket(cfg.Network, cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen: %w", err)
+		}
+		b.packets = conn
+		b.wg.Add(1)
+		go b.readPackets()
+	case "tcp", "tcp4", "tcp6":
+		l, err := net.Listen(cfg.Network, cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen: %w", err)
+		}
+		b.listener = l
+		b.wg.Add(1)
+		go b.accept()
+	default:
+		return nil, fmt.Errorf("unsupported network %q", cfg.Network)
+	}
+
+	for _, addr := range cfg.Peers {
+		if err := b.AddPeer(addr); err != nil {
+			b.Close()
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// Addr returns the local address of the bus.
+func (b *InvalidationBus[K, V]) Addr() net.Addr {
+	if b.packets != nil {
+		return b.packets.LocalAddr()
+	}
+
+	return b.listener.Addr()
+}
+
+// AddPeer adds the address to the destinations of the invalidations.
+func (b *InvalidationBus[K, V]) AddPeer(addr string) error {
+	p := &peer{addr: addr}
+	if b.packets != nil {
+		udpAddr, err := net.ResolveUDPAddr(b.cfg.Network, addr)
+		if err != nil {
+			return fmt.Errorf("resolve peer: %w", err)
+		}
+		p.udp = udpAddr
+	}
+
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.peers = append(b.peers, p)
+
+	return nil
+}
+
+// Set adds a key-value pair to the local cache and invalidates the key in the caches of the peers.
+// Returns true if the key was already present in the local cache, false otherwise.
+func (b *InvalidationBus[K, V]) Set(key K, value V) bool {
+	existed := b.cache.Set(key, value)
+	b.publishKey(key)
+
+	return existed
+}
+
+// SetWithTags adds a key-value pair with the tags to the local cache and invalidates the key
+// in the caches of the peers. Returns true if the key was already present in the local cache, false otherwise.
+func (b *InvalidationBus[K, V]) SetWithTags(key K, value V, tags ...string) bool {
+	existed := b.cache.SetWithTags(key, value, tags...)
+	b.publishKey(key)
+
+	return existed
+}
+
+// Delete removes the key from the local cache and from the caches of the peers.
+// Returns true if the key was present in the local cache, false otherwise.
+func (b *InvalidationBus[K, V]) Delete(key K) bool {
+	existed := b.cache.Delete(key)
+	b.publishKey(key)
+
+	return existed
+}
+
+// InvalidateTag removes all the keys associated with the tag from the local cache and from the caches of the peers.
+// Returns the number of the keys removed from the local cache.
+func (b *InvalidationBus[K, V]) InvalidateTag(tag string) int {
+	n := b.cache.InvalidateTag(tag)
+	b.publish(opDeleteTag, []byte(tag))
+
+	return n
+}
+
+// Close stops the bus and closes its connections. The cache is not affected.
+func (b *InvalidationBus[K, V]) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.closed = true
+	for conn := range b.conns {
+		conn.Close()
+	}
+	b.mu.Unlock()
+
+	var err error
+	if b.packets != nil {
+		err = b.packets.Close()
+	} else {
+		err = b.listener.Close()
+	}
+
+	b.sendMu.Lock()
+	for _, p := range b.peers {
+		if p.tcp != nil {
+			p.tcp.Close()
+			p.tcp = nil
+		}
+	}
+	b.sendMu.Unlock()
+
+	b.wg.Wait()
+
+	return err
+}
+
+// handleError passes a non-nil error to the error handler if it is set.
+func (b *InvalidationBus[K, V]) handleError(err error) {
+	if err != nil && b.cfg.OnError != nil {
+		b.cfg.OnError(err)
+	}
+}
+
+// isClosed reports whether the bus is closed.
+func (b *InvalidationBus[K, V]) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+// publishKey sends the invalidation of the key to the peers.
+func (b *InvalidationBus[K, V]) publishKey(key K) {
+	data, err := b.cfg.Codec.Marshal(key)
+	if err != nil {
+		b.handleError(err)
+		return
+	}
+
+	b.publish(opDeleteKey, data)
+}
+
+// publish sends the message to all the peers. The sequence number is assigned under the mutex,
+// so the messages are sent in its order.
+func (b *InvalidationBus[K, V]) publish(op byte, payload []byte) {
+	if len(payload) > maxInvalidationPayload {
+		b.handleError(fmt.Errorf("invalidation payload of %d bytes exceeds %d bytes", len(payload), maxInvalidationPayload))
+		return
+	}
+	if b.isClosed() {
+		return
+	}
+
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.seq++
+	msg := invalidation{op: op, origin: b.origin, seq: b.seq, payload: payload}.marshal()
+
+	for _, p := range b.peers {
+		b.handleError(b.send(p, msg))
+	}
+}
+
+// send writes the message to the peer. The caller must hold the send mutex.
+func (b *InvalidationBus[K, V]) send(p *peer, msg []byte) error {
+	deadline := time.Now().Add(b.cfg.WriteTimeout)
+
+	if b.packets != nil {
+		if err := b.packets.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+		if _, err := b.packets.WriteTo(msg, p.udp); err != nil {
+			return fmt.Errorf("send to %s: %w", p.addr, err)
+		}
+		return nil
+	}
+
+	if p.tcp == nil {
+		conn, err := net.DialTimeout(b.cfg.Network, p.addr, b.cfg.WriteTimeout)
+		if err != nil {
+			return fmt.Errorf("dial %s: %w", p.addr, err)
+		}
+		p.tcp = conn
+	}
+
+	// The frame is the message prefixed with its size.
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(msg)), uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	if err := p.tcp.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	if _, err := p.tcp.Write(frame); err != nil {
+		p.tcp.Close()
+		p.tcp = nil
+		return fmt.Errorf("send to %s: %w", p.addr, err)
+	}
+
+	return nil
+}
+
+// readPackets is the loop receiving the UDP messages.
+func (b *InvalidationBus[K, V]) readPackets() {
+	defer b.wg.Done()
+
+	buf := make([]byte, invalidationHeaderSize+maxInvalidationPayload)
+	for {
+		n, _, err := b.packets.ReadFrom(buf)
+		if err != nil {
+			if !b.isClosed() {
+				b.handleError(fmt.Errorf("receive: %w", err))
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		b.receive(buf[:n])
+	}
+}
+
+// accept is the loop accepting the TCP connections of the peers.
+func (b *InvalidationBus[K, V]) accept() {
+	defer b.wg.Done()
+
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !b.isClosed() {
+				b.handleError(fmt.Errorf("accept: %w", err))
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			conn.Close()
+			return
+		}
+		b.conns[conn] = struct{}{}
+		b.mu.Unlock()
+
+		b.wg.Add(1)
+		go b.readFrames(conn)
+	}
+}
+
+// readFrames is the loop receiving the TCP messages from a connection until it is closed.
+func (b *InvalidationBus[K, V]) readFrames(conn net.Conn) {
+	defer b.wg.Done()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	buf := make([]byte, invalidationHeaderSize+maxInvalidationPayload)
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			if !errors.Is(err, io.EOF) && !b.isClosed() {
+				b.handleError(fmt.Errorf("receive: %w", err))
+			}
+			return
+		}
+
+		n := binary.BigEndian.Uint32(size[:])
+		if int(n) > len(buf) {
+			b.handleError(errMalformedMessage)
+			return
+		}
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			if !b.isClosed() {
+				b.handleError(fmt.Errorf("receive: %w", err))
+			}
+			return
+		}
+
+		b.receive(buf[:n])
+	}
+}
+
+// receive decodes the message and applies it to the local cache unless it is sent by the bus itself or duplicated.
+func (b *InvalidationBus[K, V]) receive(data []byte) {
+	m, err := unmarshalInvalidation(data)
+	if err != nil {
+		b.handleError(err)
+		return
+	}
+	if m.origin == b.origin {
+		return
+	}
+
+	b.mu.Lock()
+	w, ok := b.windows[m.origin]
+	fresh, lost := true, uint64(0)
+	if ok {
+		fresh, lost = w.receive(m.seq)
+	} else {
+		b.windows[m.origin] = newSeqWindow(m.seq)
+	}
+	b.mu.Unlock()
+
+	if !fresh {
+		return
+	}
+
+	if lost > 0 {
+		if b.cfg.ClearOnLoss {
+			b.cache.Clear()
+		}
+		b.handleError(fmt.Errorf("%w: %d from origin %016x", ErrInvalidationsLost, lost, m.origin))
+	}
+
+	switch m.op {
+	case opDeleteKey:
+		key, err := b.cfg.Codec.Unmarshal(m.payload)
+		if err != nil {
+			b.handleError(err)
+			return
+		}
+		b.cache.Delete(key)
+	case opDeleteTag:
+		b.cache.InvalidateTag(string(m.payload))
+	}
+}
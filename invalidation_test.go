@@ -0,0 +1,249 @@
+package lru
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeqWindow(t *testing.T) {
+	t.Run("in order", func(t *testing.T) {
+		w := newSeqWindow(10)
+		for seq := uint64(11); seq < 200; seq++ {
+			fresh, lost := w.receive(seq)
+			require.True(t, fresh)
+			require.Zero(t, lost)
+		}
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		w := newSeqWindow(1)
+		w.receive(2)
+
+		fresh, _ := w.receive(2)
+		require.False(t, fresh)
+		fresh, _ = w.receive(1)
+		require.False(t, fresh)
+	})
+
+	t.Run("reordering within the window", func(t *testing.T) {
+		w := newSeqWindow(1)
+		_, lost := w.receive(5)
+		require.Zero(t, lost)
+
+		for _, seq := range []uint64{3, 2, 4} {
+			fresh, lost := w.receive(seq)
+			require.True(t, fresh)
+			require.Zero(t, lost)
+		}
+
+		_, lost = w.receive(6)
+		require.Zero(t, lost)
+	})
+
+	t.Run("loss is reported when the gap leaves the window", func(t *testing.T) {
+		w := newSeqWindow(1)
+		_, lost := w.receive(4) // 2 and 3 are missing.
+		require.Zero(t, lost)
+
+		_, lost = w.receive(66)
+		require.Equal(t, uint64(1), lost, "2 left the window")
+		_, lost = w.receive(67)
+		require.Equal(t, uint64(1), lost, "3 left the window")
+		_, lost = w.receive(68)
+		require.Zero(t, lost)
+	})
+
+	t.Run("jump beyond the window", func(t *testing.T) {
+		w := newSeqWindow(1)
+		w.receive(3) // 2 is missing.
+
+		_, lost := w.receive(3 + 100)
+		require.Equal(t, uint64(1+99-63), lost)
+
+		fresh, _ := w.receive(2)
+		require.True(t, fresh, "too old numbers are not tracked")
+	})
+}
+
+func TestInvalidationMessage(t *testing.T) {
+	m := invalidation{op: opDeleteTag, origin: 42, seq: 7, payload: []byte("tag")}
+
+	decoded, err := unmarshalInvalidation(m.marshal())
+	require.NoError(t, err)
+	require.Equal(t, m, decoded)
+
+	for _, data := range [][]byte{
+		nil,
+		m.marshal()[:invalidationHeaderSize],
+		append(m.marshal(), 0),
+		invalidation{op: 3}.marshal(),
+	} {
+		_, err := unmarshalInvalidation(data)
+		require.ErrorIs(t, err, errMalformedMessage)
+	}
+}
+
+// errorRecorder collects the errors reported by the buses.
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) has(target error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, err := range r.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBusMesh returns n caches connected with the buses to each other over the loopback.
+func newBusMesh(t *testing.T, network string, n int) ([]Cache[string, int], []*InvalidationBus[string, int]) {
+	t.Helper()
+
+	caches := make([]Cache[string, int], n)
+	buses := make([]*InvalidationBus[string, int], n)
+	for i := range n {
+		caches[i] = NewCache[string, int](10)
+
+		bus, err := NewInvalidationBus(caches[i], InvalidationConfig[string]{Network: network, Addr: "127.0.0.1:0"})
+		require.NoError(t, err)
+		t.Cleanup(func() { bus.Close() })
+		buses[i] = bus
+	}
+
+	for i, bus := range buses {
+		for j, other := range buses {
+			if i != j {
+				require.NoError(t, bus.AddPeer(other.Addr().String()))
+			}
+		}
+	}
+
+	return caches, buses
+}
+
+func TestInvalidationBus(t *testing.T) {
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			caches, buses := newBusMesh(t, network, 3)
+
+			for _, c := range caches {
+				c.Set("key1", 100)
+				c.SetWithTags("key2", 200, "tag")
+				c.SetWithTags("key3", 300, "tag")
+			}
+
+			require.True(t, buses[0].Set("key1", 101))
+			require.Eventually(t, func() bool {
+				_, ok1 := caches[1].Get("key1")
+				_, ok2 := caches[2].Get("key1")
+				return !ok1 && !ok2
+			}, time.Second, time.Millisecond)
+
+			v, ok := caches[0].Get("key1")
+			require.True(t, ok, "the sender keeps the new value")
+			require.Equal(t, 101, v)
+
+			require.Equal(t, 2, buses[1].InvalidateTag("tag"))
+			require.Eventually(t, func() bool {
+				return caches[0].Len() == 1 && caches[2].Len() == 0
+			}, time.Second, time.Millisecond)
+
+			require.False(t, buses[2].Delete("key1"))
+			require.Eventually(t, func() bool {
+				return caches[0].Len() == 0
+			}, time.Second, time.Millisecond)
+		})
+	}
+
+	t.Run("own messages are ignored", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		bus, err := NewInvalidationBus(c, InvalidationConfig[string]{Network: "udp", Addr: "127.0.0.1:0"})
+		require.NoError(t, err)
+		defer bus.Close()
+		require.NoError(t, bus.AddPeer(bus.Addr().String()))
+
+		bus.Set("key1", 100)
+		bus.Set("key2", 200)
+
+		// The messages come back to the bus, but they are not applied.
+		require.Never(t, func() bool {
+			return c.Len() != 2
+		}, 50*time.Millisecond, time.Millisecond)
+	})
+
+	t.Run("duplicates and losses", func(t *testing.T) {
+		errs := &errorRecorder{}
+		c := NewCache[string, int](10)
+		bus, err := NewInvalidationBus(c, InvalidationConfig[string]{
+			Network:     "udp",
+			Addr:        "127.0.0.1:0",
+			ClearOnLoss: true,
+			OnError:     errs.record,
+		})
+		require.NoError(t, err)
+		defer bus.Close()
+
+		key, err := GobCodec[string]{}.Marshal("key1")
+		require.NoError(t, err)
+		msg := func(seq uint64) []byte {
+			return invalidation{op: opDeleteKey, origin: 1, seq: seq, payload: key}.marshal()
+		}
+
+		bus.receive(msg(1))
+		c.Set("key1", 100)
+		bus.receive(msg(1))
+		require.Equal(t, 1, c.Len(), "the duplicate is ignored")
+
+		c.Set("key2", 200)
+		bus.receive(msg(100))
+		require.Zero(t, c.Len(), "the cache is cleared on the loss")
+		require.True(t, errs.has(ErrInvalidationsLost))
+	})
+
+	t.Run("unreachable tcp peer", func(t *testing.T) {
+		errs := &errorRecorder{}
+		bus, err := NewInvalidationBus(NewCache[string, int](10), InvalidationConfig[string]{
+			Network: "tcp",
+			Addr:    "127.0.0.1:0",
+			OnError: errs.record,
+		})
+		require.NoError(t, err)
+
+		other, err := NewInvalidationBus(NewCache[string, int](10), InvalidationConfig[string]{Network: "tcp", Addr: "127.0.0.1:0"})
+		require.NoError(t, err)
+		require.NoError(t, bus.AddPeer(other.Addr().String()))
+		require.NoError(t, other.Close())
+
+		bus.Delete("key1")
+		require.Eventually(t, func() bool {
+			errs.mu.Lock()
+			defer errs.mu.Unlock()
+			return len(errs.errs) > 0
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, bus.Close())
+		require.ErrorIs(t, bus.Close(), ErrClosed)
+	})
+
+	t.Run("unsupported network", func(t *testing.T) {
+		_, err := NewInvalidationBus(NewCache[string, int](10), InvalidationConfig[string]{Network: "unix"})
+		require.Error(t, err)
+	})
+}
@@ -8,8 +8,18 @@ type List[V any] interface {
 	Back() *ListItem[V]
 	PushFront(v V) *ListItem[V]
 	PushBack(v V) *ListItem[V]
-	Remove(elem *ListItem[V])
+	PushFrontList(other List[V])
+	PushBackList(other List[V])
+	InsertBefore(v V, mark *ListItem[V]) *ListItem[V]
+	InsertAfter(v V, mark *ListItem[V]) *ListItem[V]
+	Remove(elem *ListItem[V]) V
+	RemoveFunc(match func(v V) bool) int
 	MoveToFront(elem *ListItem[V])
+	MoveToBack(elem *ListItem[V])
+	MoveBefore(elem, mark *ListItem[V])
+	MoveAfter(elem, mark *ListItem[V])
+	Init() List[V]
+	Validate() error
 }
 
 // ListItem represents a basic item of the doubly-linked list.
@@ -17,12 +27,17 @@ type ListItem[V any] struct {
 	Value V
 	Next  *ListItem[V]
 	Prev  *ListItem[V]
+
+	// owner is the list the item belongs to. It is nil for the detached items.
+	owner List[V]
 }
 
 type list[V any] struct {
 	len   int
 	front *ListItem[V]
 	back  *ListItem[V]
+	// pool provides the items for the slice-backed lists. The items are allocated one by one if it is nil.
+	pool *nodePool[V]
 }
 
 // NewList returns a new list with 0 length.
@@ -47,8 +62,24 @@ func (l *list[V]) Back() *ListItem[V] {
 	return l.back
 }
 
+// newItem returns a new detached item holding the value v.
+func (l *list[V]) newItem(v V) *ListItem[V] {
+	if l.pool != nil {
+		return l.pool.get(v)
+	}
+
+	return &ListItem[V]{Value: v}
+}
+
+// owns reports whether the item belongs to the list.
+func (l *list[V]) owns(i *ListItem[V]) bool {
+	return i != nil && i.owner == List[V](l)
+}
+
 // pushFrontLogic is a helper method for PushFront and MoveToFront methods.
 func (l *list[V]) pushFrontLogic(i *ListItem[V]) *ListItem[V] {
+	i.owner = l
+
 	switch l.len {
 	case 0:
 		l.front = i
@@ -70,10 +101,35 @@ func (l *list[V]) pushFrontLogic(i *ListItem[V]) *ListItem[V] {
 	return i
 }
 
+// insertAfter links the detached item i right after prev. If prev is nil, the item is linked to the front of the list.
+func (l *list[V]) insertAfter(i, prev *ListItem[V]) *ListItem[V] {
+	var next *ListItem[V]
+	if prev == nil {
+		next = l.front
+		l.front = i
+	} else {
+		next = prev.Next
+		prev.Next = i
+	}
+
+	if next == nil {
+		l.back = i
+	} else {
+		next.Prev = i
+	}
+
+	i.Prev = prev
+	i.Next = next
+	i.owner = l
+	l.len++
+
+	return i
+}
+
 // PushFront adds the value v at the beginning of the list.
 // The function returns the item that was created for the value v.
 func (l *list[V]) PushFront(v V) *ListItem[V] {
-	newItem := &ListItem[V]{Value: v}
+	newItem := l.newItem(v)
 
 	return l.pushFrontLogic(newItem)
 }
@@ -81,7 +137,8 @@ func (l *list[V]) PushFront(v V) *ListItem[V] {
 // PushBack adds the value v at the end of the list.
 // The function returns the item that was created for the value v.
 func (l *list[V]) PushBack(v V) *ListItem[V] {
-	newItem := &ListItem[V]{Value: v}
+	newItem := l.newItem(v)
+	newItem.owner = l
 
 	switch l.len {
 	case 0:
@@ -102,14 +159,66 @@ func (l *list[V]) PushBack(v V) *ListItem[V] {
 	return newItem
 }
 
-// Remove deletes the specified ListItem from the list.
-// The length of the list is decremented by one.
-func (l *list[V]) Remove(elem *ListItem[V]) {
+// PushFrontList inserts a copy of the other list at the beginning of the list.
+// The lists may be the same.
+func (l *list[V]) PushFrontList(other List[V]) {
+	for i, elem := other.Len(), other.Back(); i > 0; i, elem = i-1, elem.Prev {
+		l.PushFront(elem.Value)
+	}
+}
+
+// PushBackList inserts a copy of the other list at the end of the list.
+// The lists may be the same.
+func (l *list[V]) PushBackList(other List[V]) {
+	for i, elem := other.Len(), other.Front(); i > 0; i, elem = i-1, elem.Next {
+		l.PushBack(elem.Value)
+	}
+}
+
+// InsertBefore inserts the value v right before mark and returns the item that was created for it.
+// If mark does not belong to the list, the list is not modified and nil is returned.
+func (l *list[V]) InsertBefore(v V, mark *ListItem[V]) *ListItem[V] {
+	if !l.owns(mark) {
+		return nil
+	}
+
+	return l.insertAfter(l.newItem(v), mark.Prev)
+}
+
+// InsertAfter inserts the value v right after mark and returns the item that was created for it.
+// If mark does not belong to the list, the list is not modified and nil is returned.
+func (l *list[V]) InsertAfter(v V, mark *ListItem[V]) *ListItem[V] {
+	if !l.owns(mark) {
+		return nil
+	}
+
+	return l.insertAfter(l.newItem(v), mark)
+}
+
+// Remove deletes the specified ListItem from the list and returns its value.
+// The length of the list is decremented by one. The links of the removed item are cleared.
+// If the item does not belong to the list, e.g. it was already removed, the list is not modified.
+func (l *list[V]) Remove(elem *ListItem[V]) V {
+	if !l.owns(elem) {
+		var zeroVal V
+		return zeroVal
+	}
+
+	l.unlink(elem)
+
+	value := elem.Value
+	if l.pool != nil {
+		l.pool.put(elem)
+	}
+
+	return value
+}
+
+// unlink is a helper method for Remove and move methods. It detaches the item of the list
+// without returning it to the pool.
+func (l *list[V]) unlink(elem *ListItem[V]) {
 	switch l.len {
-	case 0:
-		return
 	case 1:
-		// Assuming the list item is in the given list - panic otherwise.
 		l.front = nil
 		l.back = nil
 	default:
@@ -122,18 +231,95 @@ func (l *list[V]) Remove(elem *ListItem[V]) {
 			l.back.Next = nil
 		// Item is somewhere in the middle of the list.
 		default:
-			// Assuming the list item is in the given list - panic otherwise.
 			elem.Prev.Next = elem.Next
 			elem.Next.Prev = elem.Prev
 		}
 	}
 
+	elem.Next = nil
+	elem.Prev = nil
+	elem.owner = nil
 	l.len--
 }
 
+// RemoveFunc deletes all the items with values matching the predicate from the list, walking it once
+// from the front to the back. Returns the number of removed items.
+func (l *list[V]) RemoveFunc(match func(v V) bool) int {
+	n := 0
+
+	for elem := l.front; elem != nil; {
+		// Saving the next item before elem is unlinked.
+		next := elem.Next
+		if match(elem.Value) {
+			l.Remove(elem)
+			n++
+		}
+		elem = next
+	}
+
+	return n
+}
+
 // MoveToFront moves item i to the front of the list.
-// For an empty list function has the similar behavior as PushFront method.
+// If the item does not belong to the list, the list is not modified.
 func (l *list[V]) MoveToFront(elem *ListItem[V]) {
-	l.Remove(elem)
+	if !l.owns(elem) || elem == l.front {
+		return
+	}
+
+	l.unlink(elem)
 	l.pushFrontLogic(elem)
 }
+
+// MoveToBack moves the item to the back of the list.
+// If the item does not belong to the list, the list is not modified.
+func (l *list[V]) MoveToBack(elem *ListItem[V]) {
+	if !l.owns(elem) || elem == l.back {
+		return
+	}
+
+	l.unlink(elem)
+	l.insertAfter(elem, l.back)
+}
+
+// MoveBefore moves the item right before mark.
+// If the item is mark itself or any of them does not belong to the list, the list is not modified.
+func (l *list[V]) MoveBefore(elem, mark *ListItem[V]) {
+	if elem == mark || !l.owns(elem) || !l.owns(mark) {
+		return
+	}
+
+	l.unlink(elem)
+	l.insertAfter(elem, mark.Prev)
+}
+
+// MoveAfter moves the item right after mark.
+// If the item is mark itself or any of them does not belong to the list, the list is not modified.
+func (l *list[V]) MoveAfter(elem, mark *ListItem[V]) {
+	if elem == mark || !l.owns(elem) || !l.owns(mark) {
+		return
+	}
+
+	l.unlink(elem)
+	l.insertAfter(elem, mark)
+}
+
+// Init clears the list and returns it. The removed items are detached from the list.
+func (l *list[V]) Init() List[V] {
+	for elem := l.front; elem != nil; {
+		next := elem.Next
+		elem.Next = nil
+		elem.Prev = nil
+		elem.owner = nil
+		if l.pool != nil {
+			l.pool.put(elem)
+		}
+		elem = next
+	}
+
+	l.len = 0
+	l.front = nil
+	l.back = nil
+
+	return l
+}
@@ -76,12 +76,12 @@ func emptyListRemove(t *testing.T) {
 func emptyListMoveToFront(t *testing.T) {
 	t.Helper()
 
+	// The item does not belong to the list, so it is not moved.
 	l := NewList[any]()
 	elem := &ListItem[any]{Value: testValue}
 	l.MoveToFront(elem)
 
-	verifyMoveToFront(t, l, 1, elem)
-	require.Equal(t, l.Front(), l.Back())
+	verifyListStructure(t, l, 0, nil, nil)
 }
 
 func singleElementList(t *testing.T) {
@@ -302,13 +302,17 @@ func complexBehavior(t *testing.T) {
 
 type BehaviorTestSuite struct {
 	suite.Suite
+	newList  func() List[any]
 	l        List[any]
 	cycleLen int
 	expected []int
 }
 
 func (s *BehaviorTestSuite) SetupTest() {
-	s.l = NewList[any]()
+	if s.newList == nil {
+		s.newList = NewList[any]
+	}
+	s.l = s.newList()
 
 	s.cycleLen = 10
 	s.expected = make([]int, 0, s.cycleLen)
@@ -346,6 +350,117 @@ func (s *BehaviorTestSuite) TestCyclicMoveToFront() {
 	s.Require().Equal(s.expected, s.getList(s.l))
 }
 
+func (s *BehaviorTestSuite) TestRemoveFunc() {
+	s.Require().Equal(0, s.l.RemoveFunc(func(v any) bool { return v.(int) > 100 }))
+	s.Require().Equal(s.expected, s.getList(s.l))
+
+	// Removing the front, the back and some items in the middle.
+	n := s.l.RemoveFunc(func(v any) bool { return v.(int)%30 == 0 })
+	s.Require().Equal(4, n)
+	s.Require().Equal([]int{10, 20, 40, 50, 70, 80}, s.getList(s.l))
+	s.Require().Equal(6, s.l.Len())
+	s.Require().Nil(s.l.Front().Prev)
+	s.Require().Nil(s.l.Back().Next)
+
+	s.Require().Equal(6, s.l.RemoveFunc(func(any) bool { return true }))
+	s.Require().Equal(0, s.l.Len())
+	s.Require().Nil(s.l.Front())
+	s.Require().Nil(s.l.Back())
+}
+
+func (s *BehaviorTestSuite) TestInsert() {
+	front, back := s.l.Front(), s.l.Back()
+	middle := front.Next.Next // 20
+
+	s.l.InsertBefore(-1, front)
+	s.l.InsertAfter(100, back)
+	s.l.InsertBefore(15, middle)
+	s.l.InsertAfter(25, middle)
+
+	s.verifyList([]int{-1, 0, 10, 15, 20, 25, 30, 40, 50, 60, 70, 80, 90, 100})
+}
+
+func (s *BehaviorTestSuite) TestMoveToBack() {
+	s.l.MoveToBack(s.l.Back())
+	s.verifyList(s.expected)
+
+	s.l.MoveToBack(s.l.Front())
+	s.l.MoveToBack(s.l.Front().Next) // 20
+	s.verifyList([]int{10, 30, 40, 50, 60, 70, 80, 90, 0, 20})
+}
+
+func (s *BehaviorTestSuite) TestMoveBeforeAndAfter() {
+	front, back := s.l.Front(), s.l.Back()
+	middle := front.Next.Next // 20
+
+	s.l.MoveBefore(middle, middle)
+	s.l.MoveAfter(middle, middle)
+	s.verifyList(s.expected)
+
+	s.l.MoveBefore(back, front)
+	s.verifyList([]int{90, 0, 10, 20, 30, 40, 50, 60, 70, 80})
+
+	s.l.MoveAfter(back, s.l.Back())
+	s.verifyList(s.expected)
+
+	s.l.MoveAfter(front, middle)
+	s.l.MoveBefore(s.l.Back(), middle)
+	s.verifyList([]int{10, 90, 20, 0, 30, 40, 50, 60, 70, 80})
+
+	// Moving the item next to its neighbour.
+	s.l.MoveBefore(middle, middle.Prev)
+	s.l.MoveAfter(s.l.Front(), s.l.Front().Next)
+	s.verifyList([]int{20, 10, 90, 0, 30, 40, 50, 60, 70, 80})
+}
+
+func (s *BehaviorTestSuite) TestPushLists() {
+	other := NewList[any]()
+	other.PushBack(1)
+	other.PushBack(2)
+
+	s.l.PushFrontList(other)
+	s.l.PushBackList(other)
+	s.verifyList([]int{1, 2, 0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 1, 2})
+
+	// Pushing the list to itself.
+	other.PushBackList(other)
+	other.PushFrontList(other)
+	s.Require().Equal([]int{1, 2, 1, 2, 1, 2, 1, 2}, s.getList(other))
+
+	// Pushing an empty list.
+	s.l.PushBackList(NewList[any]())
+	s.l.PushFrontList(NewList[any]())
+	s.Require().Equal(14, s.l.Len())
+}
+
+func (s *BehaviorTestSuite) TestInit() {
+	s.Require().Equal(s.l, s.l.Init())
+	s.verifyList([]int{})
+
+	s.l.PushBack(1)
+	s.verifyList([]int{1})
+}
+
+// verifyList checks the values of the list and its consistency in both directions.
+func (s *BehaviorTestSuite) verifyList(expected []int) {
+	s.Require().Equal(expected, s.getList(s.l))
+	s.Require().Equal(len(expected), s.l.Len())
+
+	backward := make([]int, 0, len(expected))
+	for i := s.l.Back(); i != nil; i = i.Prev {
+		backward = append([]int{i.Value.(int)}, backward...)
+	}
+	s.Require().Equal(expected, backward)
+
+	if len(expected) == 0 {
+		s.Require().Nil(s.l.Front())
+		s.Require().Nil(s.l.Back())
+		return
+	}
+	s.Require().Nil(s.l.Front().Prev)
+	s.Require().Nil(s.l.Back().Next)
+}
+
 func (s *BehaviorTestSuite) getList(l List[any]) []int {
 	elems := make([]int, 0, s.cycleLen)
 	for i := l.Front(); i != nil; i = i.Next {
@@ -401,4 +516,68 @@ func TestList(t *testing.T) {
 	t.Run("list with a single element", func(t *testing.T) { singleElementList(t) })
 	t.Run("list with 2 elements", func(t *testing.T) { twoElementList(t) })
 	t.Run("complex behavior", func(t *testing.T) { complexBehavior(t) })
+	t.Run("item ownership", itemOwnership)
+}
+
+func itemOwnership(t *testing.T) {
+	t.Helper()
+
+	t.Run("remove returns the value", func(t *testing.T) {
+		l := NewList[any]()
+		first := l.PushBack(10)
+		second := l.PushBack(20)
+
+		require.Equal(t, 10, l.Remove(first))
+		require.Nil(t, first.Next)
+		require.Nil(t, first.Prev)
+		verifyListStructure(t, l, 1, second, second)
+	})
+
+	t.Run("removed item", func(t *testing.T) {
+		l := NewList[any]()
+		first := l.PushBack(10)
+		second := l.PushBack(20)
+		l.Remove(first)
+
+		require.Nil(t, l.Remove(first))
+		l.MoveToFront(first)
+		l.MoveToBack(first)
+		l.MoveBefore(first, second)
+		l.MoveAfter(second, first)
+		require.Nil(t, l.InsertBefore(30, first))
+		require.Nil(t, l.InsertAfter(30, first))
+
+		verifyListStructure(t, l, 1, second, second)
+	})
+
+	t.Run("foreign item", func(t *testing.T) {
+		l, other := NewList[any](), NewList[any]()
+		first := l.PushBack(10)
+		second := l.PushBack(20)
+		foreign := other.PushBack(30)
+
+		require.Nil(t, l.Remove(foreign))
+		l.MoveToFront(foreign)
+		l.MoveAfter(foreign, first)
+		l.MoveBefore(first, foreign)
+		require.Nil(t, l.InsertAfter(40, foreign))
+		require.Nil(t, l.InsertBefore(40, nil))
+
+		verifyListStructure(t, l, 2, first, second)
+		verifyListStructure(t, other, 1, foreign, foreign)
+	})
+
+	t.Run("init detaches the items", func(t *testing.T) {
+		l := NewList[any]()
+		first := l.PushBack(10)
+		l.PushBack(20)
+		l.Init()
+
+		require.Nil(t, l.Remove(first))
+		require.Nil(t, first.Next)
+
+		third := l.PushBack(30)
+		l.MoveToFront(first)
+		verifyListStructure(t, l, 1, third, third)
+	})
 }
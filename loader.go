@@ -0,0 +1,152 @@
+package lru
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBatchLoadAborted is returned to the callers waiting for a batch load if the batch loader
+// panicked before returning.
+var ErrBatchLoadAborted = errors.New("batch load aborted")
+
+// BatchLoader loads the values for the given keys from a backend in a single call.
+// Keys missing from the returned map are treated as nonexistent and are not stored in the cache.
+type BatchLoader[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// loadBatch represents a single in-flight batch loader call shared by all the callers waiting for its keys.
+type loadBatch[K comparable, V any] struct {
+	done   chan struct{}
+	values map[K]V
+	err    error
+	// stale are the keys written or removed while being loaded, their loaded values are not stored in the cache.
+	// It is guarded by the cache mutex.
+	stale map[K]struct{}
+}
+
+// wait blocks until the batch is completed or ctx is done. Returns the batch error or the context error.
+// A completed batch is always preferred over a done context.
+func (b *loadBatch[K, V]) wait(ctx context.Context) error {
+	select {
+	case <-b.done:
+		return b.err
+	default:
+	}
+
+	select {
+	case <-b.done:
+		return b.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetManyOrLoad returns the values for the given keys, loading the keys missing from the cache
+// with a single batchLoader call. Keys which are already being loaded by other goroutines are not passed
+// to batchLoader - their results are awaited instead. Loaded values are stored in the cache in the order of keys,
+// unless the keys have been written or removed during the load, since the loaded values may be older.
+// Keys which are neither cached nor returned by the loader are not present in the resulting map.
+// Returns the loader error if any of the batches the result depends on has failed,
+// or the context error if ctx is done while waiting for other goroutines.
+func (c *lruCache[K, V]) GetManyOrLoad(
+	ctx context.Context,
+	keys []K,
+	batchLoader BatchLoader[K, V],
+) (map[K]V, error) {
+	res := make(map[K]V, len(keys))
+	pending := make(map[K]*loadBatch[K, V])
+	missing := make([]K, 0, len(keys))
+
+	var own *loadBatch[K, V]
+
+	c.lock()
+	for _, key := range keys {
+		if _, ok := res[key]; ok {
+			continue
+		}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+
+		if v, ok := c.get(key); ok {
+			res[key] = v
+			continue
+		}
+
+		// The key is being loaded by another goroutine -> waiting for it instead of loading it once again.
+		if b, ok := c.loading[key]; ok {
+			pending[key] = b
+			continue
+		}
+
+		if own == nil {
+			own = &loadBatch[K, V]{done: make(chan struct{})}
+		}
+		c.loading[key] = own
+		pending[key] = own
+		missing = append(missing, key)
+	}
+	c.unlock()
+
+	if own != nil {
+		c.runBatch(ctx, own, missing, batchLoader)
+	}
+
+	for key, b := range pending {
+		if err := b.wait(ctx); err != nil {
+			return nil, err
+		}
+		if v, ok := b.values[key]; ok {
+			res[key] = v
+		}
+	}
+
+	return res, nil
+}
+
+// runBatch calls the loader for the keys of the batch and publishes its results.
+// The batch is completed even if the loader panics, so the waiting goroutines are never blocked forever.
+func (c *lruCache[K, V]) runBatch(ctx context.Context, b *loadBatch[K, V], keys []K, loader BatchLoader[K, V]) {
+	values, err := map[K]V(nil), ErrBatchLoadAborted
+
+	defer func() {
+		c.lock()
+		defer c.unlock()
+
+		for _, key := range keys {
+			delete(c.loading, key)
+
+			if err != nil {
+				continue
+			}
+			if _, ok := b.stale[key]; ok {
+				continue
+			}
+			if _, ok := c.items[key]; ok {
+				continue
+			}
+			if v, ok := values[key]; ok {
+				c.set(key, v)
+			}
+		}
+
+		b.values, b.err = values, err
+		close(b.done)
+	}()
+
+	values, err = loader(ctx, keys)
+}
+
+// abandonLoad marks the key written or removed while it is being loaded, so the loaded value does not undo the change.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) abandonLoad(key K) {
+	if len(c.loading) == 0 {
+		return
+	}
+
+	if b, ok := c.loading[key]; ok {
+		if b.stale == nil {
+			b.stale = make(map[K]struct{})
+		}
+		b.stale[key] = struct{}{}
+	}
+}
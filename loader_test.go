@@ -0,0 +1,260 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errBackend = errors.New("backend failure")
+
+// mapLoader returns a batch loader which serves the values from src and records the requested keys.
+func mapLoader(src map[string]int, calls *[][]string) BatchLoader[string, int] {
+	return func(_ context.Context, keys []string) (map[string]int, error) {
+		*calls = append(*calls, append([]string(nil), keys...))
+
+		res := make(map[string]int, len(keys))
+		for _, k := range keys {
+			if v, ok := src[k]; ok {
+				res[k] = v
+			}
+		}
+		return res, nil
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	c := NewCache[string, int](3)
+	c.Set("key1", 100)
+	c.Set("key2", 200)
+	c.Set("key3", 300)
+
+	res := c.GetMany([]string{"key1", "key4", "key1"})
+	require.Equal(t, map[string]int{"key1": 100}, res)
+
+	// key1 was promoted, so key2 is the oldest one.
+	c.Set("key4", 400)
+	_, ok := c.Get("key2")
+	require.False(t, ok)
+	_, ok = c.Get("key1")
+	require.True(t, ok)
+}
+
+func TestGetManyOrLoad(t *testing.T) {
+	src := map[string]int{"key1": 100, "key2": 200, "key3": 300, "key4": 400}
+
+	t.Run("only missing keys are loaded", func(t *testing.T) {
+		var calls [][]string
+		c := NewCache[string, int](10)
+		c.Set("key1", 101)
+
+		res, err := c.GetManyOrLoad(context.Background(), []string{"key1", "key2", "key3", "key2"}, mapLoader(src, &calls))
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"key1": 101, "key2": 200, "key3": 300}, res)
+		require.Equal(t, [][]string{{"key2", "key3"}}, calls)
+
+		// Loaded values are stored in the cache.
+		res, err = c.GetManyOrLoad(context.Background(), []string{"key2", "key3"}, mapLoader(src, &calls))
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"key2": 200, "key3": 300}, res)
+		require.Len(t, calls, 1)
+	})
+
+	t.Run("nonexistent keys", func(t *testing.T) {
+		var calls [][]string
+		c := NewCache[string, int](10)
+
+		res, err := c.GetManyOrLoad(context.Background(), []string{"key1", "key5"}, mapLoader(src, &calls))
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"key1": 100}, res)
+
+		_, ok := c.Get("key5")
+		require.False(t, ok)
+	})
+
+	t.Run("insertion order", func(t *testing.T) {
+		var calls [][]string
+		c := NewCache[string, int](2)
+
+		_, err := c.GetManyOrLoad(context.Background(), []string{"key1", "key2", "key3"}, mapLoader(src, &calls))
+		require.NoError(t, err)
+
+		// key1 was inserted first, so it was evicted.
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+		require.Equal(t, map[string]int{"key2": 200, "key3": 300}, c.GetMany([]string{"key2", "key3"}))
+	})
+
+	t.Run("loader error", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		loader := func(context.Context, []string) (map[string]int, error) {
+			return map[string]int{"key1": 100}, errBackend
+		}
+
+		res, err := c.GetManyOrLoad(context.Background(), []string{"key1"}, loader)
+		require.ErrorIs(t, err, errBackend)
+		require.Nil(t, res)
+
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+	})
+
+	t.Run("loader panic", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		loader := func(context.Context, []string) (map[string]int, error) {
+			panic("boom")
+		}
+
+		require.Panics(t, func() {
+			_, _ = c.GetManyOrLoad(context.Background(), []string{"key1"}, loader)
+		})
+
+		// The key is no longer marked as being loaded.
+		var calls [][]string
+		res, err := c.GetManyOrLoad(context.Background(), []string{"key1"}, mapLoader(src, &calls))
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"key1": 100}, res)
+	})
+
+	t.Run("concurrent loads are deduplicated", concurrentBatchLoads)
+	t.Run("context is done while waiting", contextDoneWhileWaiting)
+	t.Run("changes during the load are kept", changesDuringLoad)
+}
+
+func changesDuringLoad(t *testing.T) {
+	c := NewCache[string, int](10)
+	started, release := make(chan struct{}), make(chan struct{})
+	loader := func(_ context.Context, keys []string) (map[string]int, error) {
+		close(started)
+		<-release
+
+		res := make(map[string]int, len(keys))
+		for _, k := range keys {
+			res[k] = 1
+		}
+		return res, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.GetManyOrLoad(context.Background(), []string{"set", "deleted", "set-deleted", "untouched"}, loader)
+	}()
+	<-started
+
+	c.Set("set", 100)
+	c.Delete("deleted")
+	c.Set("set-deleted", 200)
+	c.Delete("set-deleted")
+	close(release)
+	<-done
+
+	require.Equal(t, map[string]int{"set": 100, "untouched": 1},
+		c.GetMany([]string{"set", "deleted", "set-deleted", "untouched"}))
+
+	// Clear drops the values being loaded as well.
+	started, release, done = make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		c.GetManyOrLoad(context.Background(), []string{"cleared"}, loader)
+	}()
+	<-started
+
+	c.Clear()
+	close(release)
+	<-done
+
+	require.Zero(t, c.Len())
+}
+
+func concurrentBatchLoads(t *testing.T) {
+	t.Helper()
+
+	c := NewCache[string, int](10)
+	started, release := make(chan struct{}), make(chan struct{})
+
+	mu := &sync.Mutex{}
+	var calls [][]string
+	loader := func(_ context.Context, keys []string) (map[string]int, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		first := len(calls) == 1
+		mu.Unlock()
+
+		if first {
+			close(started)
+			<-release
+		}
+
+		res := make(map[string]int, len(keys))
+		for _, k := range keys {
+			res[k] = len(k)
+		}
+		return res, nil
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		res, err := c.GetManyOrLoad(context.Background(), []string{"a", "bb"}, loader)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"a": 1, "bb": 2}, res)
+	}()
+	<-started
+
+	resCh := make(chan map[string]int)
+	go func() {
+		res, err := c.GetManyOrLoad(context.Background(), []string{"bb", "ccc"}, loader)
+		require.NoError(t, err)
+		resCh <- res
+	}()
+
+	// Waiting for the second batch to be loaded before letting the first one finish.
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	require.Equal(t, map[string]int{"bb": 2, "ccc": 3}, <-resCh)
+	wg.Wait()
+	require.Equal(t, [][]string{{"a", "bb"}, {"ccc"}}, calls)
+}
+
+func contextDoneWhileWaiting(t *testing.T) {
+	t.Helper()
+
+	c := NewCache[string, int](10)
+	started, release := make(chan struct{}), make(chan struct{})
+	loader := func(_ context.Context, keys []string) (map[string]int, error) {
+		close(started)
+		<-release
+		return map[string]int{"key1": 100}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = c.GetManyOrLoad(context.Background(), []string{"key1"}, loader)
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := c.GetManyOrLoad(ctx, []string{"key1"}, loader)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Nil(t, res)
+
+	close(release)
+	<-done
+
+	v, ok := c.Get("key1")
+	require.True(t, ok)
+	require.Equal(t, 100, v)
+}
@@ -0,0 +1,358 @@
+package lrutest
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// OpKind is the kind of a recorded cache operation.
+type OpKind int
+
+const (
+	// OpSet is a Set call.
+	OpSet OpKind = iota
+	// OpGet is a Get call.
+	OpGet
+	// OpClear is a Clear call.
+	OpClear
+)
+
+// String returns the name of the operation kind.
+func (k OpKind) String() string {
+	switch k {
+	case OpSet:
+		return "set"
+	case OpGet:
+		return "get"
+	case OpClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+// Operation is a completed cache call recorded by the Recorder.
+type Operation struct {
+	// Client is the identifier of the goroutine which made the call.
+	Client int
+	Kind   OpKind
+	Key    string
+	// Value is the value passed to Set or returned by Get.
+	Value int
+	// Ok is the result of Set or the presence of the key returned by Get.
+	Ok bool
+	// Call and Return are the logical timestamps of the start and the end of the call.
+	// An operation precedes another one if it returned before the other one was called.
+	Call, Return int64
+}
+
+// String returns the operation in a human-readable form.
+func (op Operation) String() string {
+	switch op.Kind {
+	case OpSet:
+		return fmt.Sprintf("[%d, %d] client %d: set(%s, %d) = %t", op.Call, op.Return, op.Client, op.Key, op.Value, op.Ok)
+	case OpGet:
+		return fmt.Sprintf("[%d, %d] client %d: get(%s) = %d, %t", op.Call, op.Return, op.Client, op.Key, op.Value, op.Ok)
+	default:
+		return fmt.Sprintf("[%d, %d] client %d: %s()", op.Call, op.Return, op.Client, op.Kind)
+	}
+}
+
+// Recorder records the history of the operations made by concurrent clients on a cache.
+// It is safe for concurrent use.
+type Recorder struct {
+	cache Cache
+	clock atomic.Int64
+
+	mu  sync.Mutex
+	ops []Operation
+}
+
+// NewRecorder returns a new Recorder of the operations on the cache.
+func NewRecorder(c Cache) *Recorder {
+	return &Recorder{cache: c}
+}
+
+// Set calls Set on the cache on behalf of the client and records the operation.
+func (r *Recorder) Set(client int, key string, value int) bool {
+	call := r.clock.Add(1)
+	ok := r.cache.Set(key, value)
+	r.record(Operation{Client: client, Kind: OpSet, Key: key, Value: value, Ok: ok, Call: call})
+
+	return ok
+}
+
+// Get calls Get on the cache on behalf of the client and records the operation.
+func (r *Recorder) Get(client int, key string) (int, bool) {
+	call := r.clock.Add(1)
+	v, ok := r.cache.Get(key)
+	r.record(Operation{Client: client, Kind: OpGet, Key: key, Value: v, Ok: ok, Call: call})
+
+	return v, ok
+}
+
+// Clear calls Clear on the cache on behalf of the client and records the operation.
+func (r *Recorder) Clear(client int) {
+	call := r.clock.Add(1)
+	r.cache.Clear()
+	r.record(Operation{Client: client, Kind: OpClear, Call: call})
+}
+
+// History returns the recorded operations.
+func (r *Recorder) History() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.ops)
+}
+
+// record stamps the return time of the operation and adds it to the history.
+func (r *Recorder) record(op Operation) {
+	op.Return = r.clock.Add(1)
+
+	r.mu.Lock()
+	r.ops = append(r.ops, op)
+	r.mu.Unlock()
+}
+
+// CheckLinearizable reports whether the history is linearizable with respect to a sequential LRU cache
+// of the given capacity, which is empty initially. That is, whether the operations can be ordered, respecting
+// their real-time order, so that every result matches the one of the sequential cache.
+// The search is exponential in the worst case, so the histories should be kept small.
+func CheckLinearizable(capacity int, history []Operation) bool {
+	ops := slices.Clone(history)
+	slices.SortFunc(ops, func(a, b Operation) int { return cmp.Compare(a.Call, b.Call) })
+
+	c := &checker{
+		ops:     ops,
+		done:    make([]uint64, (len(ops)+63)/64),
+		visited: make(map[string]struct{}),
+	}
+
+	return c.search(len(ops), lruModel{capacity: capacity})
+}
+
+// checker searches for a linearization of the operations sorted by their call time.
+type checker struct {
+	ops []Operation
+	// done is the bitset of the linearized operations.
+	done []uint64
+	// visited keeps the explored pairs of the linearized operations and the model states.
+	visited map[string]struct{}
+}
+
+// search tries to linearize the remaining operations starting from the model state.
+func (c *checker) search(remaining int, m lruModel) bool {
+	if remaining == 0 {
+		return true
+	}
+
+	key := c.stateKey(m)
+	if _, ok := c.visited[key]; ok {
+		return false
+	}
+	c.visited[key] = struct{}{}
+
+	// Only the operations called before the earliest return of the pending ones may go next.
+	minReturn := int64(math.MaxInt64)
+	for i, op := range c.ops {
+		if !c.isDone(i) {
+			minReturn = min(minReturn, op.Return)
+		}
+	}
+
+	for i, op := range c.ops {
+		if op.Call > minReturn {
+			break
+		}
+		if c.isDone(i) {
+			continue
+		}
+
+		next, ok := m.apply(op)
+		if !ok {
+			continue
+		}
+
+		c.setDone(i, true)
+		if c.search(remaining-1, next) {
+			return true
+		}
+		c.setDone(i, false)
+	}
+
+	return false
+}
+
+func (c *checker) isDone(i int) bool {
+	return c.done[i/64]&(1<<(i%64)) != 0
+}
+
+func (c *checker) setDone(i int, done bool) {
+	if done {
+		c.done[i/64] |= 1 << (i % 64)
+	} else {
+		c.done[i/64] &^= 1 << (i % 64)
+	}
+}
+
+// stateKey encodes the linearized operations and the model state as a map key.
+func (c *checker) stateKey(m lruModel) string {
+	b := make([]byte, 0, len(c.done)*8+len(m.entries)*8)
+	for _, w := range c.done {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+
+	for _, e := range m.entries {
+		b = append(b, '|')
+		b = strconv.AppendQuote(b, e.key)
+		b = strconv.AppendInt(b, int64(e.value), 10)
+	}
+
+	return string(b)
+}
+
+// lruModel is a sequential LRU cache keeping the entries ordered from the most recently used one.
+// It is immutable, apply returns a modified copy.
+type lruModel struct {
+	capacity int
+	entries  []modelEntry
+}
+
+type modelEntry struct {
+	key   string
+	value int
+}
+
+// apply applies the operation to a copy of the model. Returns false if the result of the operation
+// differs from the one of the model.
+func (m lruModel) apply(op Operation) (lruModel, bool) {
+	switch op.Kind {
+	case OpSet:
+		i := m.find(op.Key)
+		if op.Ok != (i >= 0) {
+			return m, false
+		}
+
+		entries := make([]modelEntry, 0, m.capacity)
+		entries = append(entries, modelEntry{op.Key, op.Value})
+		for j, e := range m.entries {
+			if j != i && len(entries) < m.capacity {
+				entries = append(entries, e)
+			}
+		}
+
+		return lruModel{capacity: m.capacity, entries: entries}, true
+	case OpGet:
+		i := m.find(op.Key)
+		if op.Ok != (i >= 0) || (i >= 0 && op.Value != m.entries[i].value) {
+			return m, false
+		}
+		if i <= 0 {
+			return m, true
+		}
+
+		entries := make([]modelEntry, 0, len(m.entries))
+		entries = append(entries, m.entries[i])
+		entries = append(entries, m.entries[:i]...)
+		entries = append(entries, m.entries[i+1:]...)
+
+		return lruModel{capacity: m.capacity, entries: entries}, true
+	case OpClear:
+		return lruModel{capacity: m.capacity}, true
+	default:
+		return m, false
+	}
+}
+
+func (m lruModel) find(key string) int {
+	return slices.IndexFunc(m.entries, func(e modelEntry) bool { return e.key == key })
+}
+
+// LinearizabilityConfig configures RunLinearizability. Zero fields take the default values.
+type LinearizabilityConfig struct {
+	// Capacity is the capacity of the cache under test. Defaults to 2.
+	Capacity int
+	// Goroutines is the number of the concurrent clients. Defaults to 4.
+	Goroutines int
+	// Operations is the number of the operations made by every client. Defaults to 50.
+	Operations int
+	// Keys is the number of the distinct keys used by the clients. Defaults to 4.
+	Keys int
+	// Rounds is the number of the checked histories, every one made on a new cache. Defaults to 10.
+	Rounds int
+}
+
+// RunLinearizability runs random concurrent Set, Get and Clear calls on the caches returned by newCache
+// and checks that their histories are linearizable with respect to a sequential LRU cache.
+// It is meant to be run with the race detector enabled.
+func RunLinearizability(t *testing.T, newCache Factory, cfg LinearizabilityConfig) {
+	t.Helper()
+
+	cfg.Capacity = withDefault(cfg.Capacity, 2)
+	cfg.Goroutines = withDefault(cfg.Goroutines, 4)
+	cfg.Operations = withDefault(cfg.Operations, 50)
+	cfg.Keys = withDefault(cfg.Keys, 4)
+	cfg.Rounds = withDefault(cfg.Rounds, 10)
+
+	for round := range cfg.Rounds {
+		r := NewRecorder(newCache(cfg.Capacity))
+		wg := &sync.WaitGroup{}
+		wg.Add(cfg.Goroutines)
+
+		for client := range cfg.Goroutines {
+			go func() {
+				defer wg.Done()
+				rnd := rand.New(rand.NewPCG(uint64(round), uint64(client)))
+
+				for i := range cfg.Operations {
+					key := "key" + strconv.Itoa(rnd.IntN(cfg.Keys))
+					switch n := rnd.IntN(100); {
+					case n < 45:
+						r.Set(client, key, client*cfg.Operations+i)
+					case n < 98:
+						r.Get(client, key)
+					default:
+						r.Clear(client)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		history := r.History()
+		if !CheckLinearizable(cfg.Capacity, history) {
+			t.Errorf("round %d: history is not linearizable:\n%s", round, formatHistory(history))
+			return
+		}
+	}
+}
+
+func withDefault(v, def int) int {
+	if v < 1 {
+		return def
+	}
+	return v
+}
+
+// formatHistory returns the operations ordered by their call time, one per line.
+func formatHistory(history []Operation) string {
+	ops := slices.Clone(history)
+	slices.SortFunc(ops, func(a, b Operation) int { return cmp.Compare(a.Call, b.Call) })
+
+	lines := make([]string, len(ops))
+	for i, op := range ops {
+		lines[i] = op.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,157 @@
+package lrutest_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Averlex/lru/lrutest"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedCache is sliceCache guarded by a mutex.
+type lockedCache struct {
+	mu    sync.Mutex
+	cache sliceCache
+}
+
+func (c *lockedCache) Set(key string, value int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Set(key, value)
+}
+
+func (c *lockedCache) Get(key string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(key)
+}
+
+func (c *lockedCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Clear()
+}
+
+func set(client int, key string, value int, existed bool, call, ret int64) lrutest.Operation {
+	return lrutest.Operation{Client: client, Kind: lrutest.OpSet, Key: key, Value: value, Ok: existed, Call: call, Return: ret}
+}
+
+func get(client int, key string, value int, ok bool, call, ret int64) lrutest.Operation {
+	return lrutest.Operation{Client: client, Kind: lrutest.OpGet, Key: key, Value: value, Ok: ok, Call: call, Return: ret}
+}
+
+func TestCheckLinearizable(t *testing.T) {
+	tests := []struct {
+		name         string
+		capacity     int
+		history      []lrutest.Operation
+		linearizable bool
+	}{
+		{
+			name:         "empty",
+			capacity:     1,
+			linearizable: true,
+		},
+		{
+			name:     "sequential",
+			capacity: 2,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 2),
+				set(0, "b", 2, false, 3, 4),
+				get(0, "a", 1, true, 5, 6),
+				set(0, "c", 3, false, 7, 8),
+				get(0, "b", 0, false, 9, 10),
+			},
+			linearizable: true,
+		},
+		{
+			name:     "wrong eviction",
+			capacity: 2,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 2),
+				set(0, "b", 2, false, 3, 4),
+				get(0, "a", 1, true, 5, 6),
+				set(0, "c", 3, false, 7, 8),
+				get(0, "a", 0, false, 9, 10),
+			},
+		},
+		{
+			name:     "concurrent get sees either value",
+			capacity: 1,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 2),
+				set(0, "a", 2, true, 3, 6),
+				get(1, "a", 1, true, 4, 5),
+			},
+			linearizable: true,
+		},
+		{
+			name:     "stale read",
+			capacity: 1,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 2),
+				set(0, "a", 2, true, 3, 4),
+				get(1, "a", 1, true, 5, 6),
+			},
+		},
+		{
+			name:     "concurrent sets of a new key",
+			capacity: 2,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 4),
+				set(1, "a", 2, true, 2, 3),
+				get(0, "a", 2, true, 5, 6),
+			},
+			linearizable: true,
+		},
+		{
+			name:     "both sets report a new key",
+			capacity: 2,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 4),
+				set(1, "a", 2, false, 2, 3),
+			},
+		},
+		{
+			name:     "clear",
+			capacity: 2,
+			history: []lrutest.Operation{
+				set(0, "a", 1, false, 1, 2),
+				{Client: 1, Kind: lrutest.OpClear, Call: 3, Return: 6},
+				get(0, "a", 1, true, 4, 5),
+				get(0, "a", 0, false, 7, 8),
+			},
+			linearizable: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.linearizable, lrutest.CheckLinearizable(tc.capacity, tc.history))
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := lrutest.NewRecorder(&sliceCache{capacity: 1})
+	require.False(t, r.Set(0, "a", 1))
+	v, ok := r.Get(1, "a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	r.Clear(0)
+
+	history := r.History()
+	require.Len(t, history, 3)
+	require.Equal(t, lrutest.OpGet, history[1].Kind)
+	require.Equal(t, 1, history[1].Client)
+	for i := 1; i < len(history); i++ {
+		require.Less(t, history[i-1].Return, history[i].Call)
+	}
+	require.True(t, lrutest.CheckLinearizable(1, history))
+}
+
+func TestRunLinearizability(t *testing.T) {
+	lrutest.RunLinearizability(t, func(capacity int) lrutest.Cache {
+		return &lockedCache{cache: sliceCache{capacity: capacity}}
+	}, lrutest.LinearizabilityConfig{Goroutines: 3})
+}
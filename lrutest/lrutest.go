@@ -0,0 +1,287 @@
+// Package lrutest provides a conformance test suite for LRU cache implementations.
+package lrutest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// Cache is the subset of the cache methods covered by the conformance suite.
+// lru.Cache[string, int] and the other caches of the lru package with string keys and int values implement it.
+type Cache interface {
+	// Set returns true if the key was already present in the cache, false otherwise.
+	Set(key string, value int) bool
+	// Get returns the value and its presence in the cache, also promotes the accessed key.
+	Get(key string) (int, bool)
+	// Clear removes all stored items from the cache.
+	Clear()
+}
+
+// Factory returns a new empty cache with the given capacity.
+type Factory func(capacity int) Cache
+
+// Run checks the caches returned by newCache against the contract of an LRU cache:
+// the return value of Set, promotion of the accessed keys, eviction order and Clear.
+func Run(t *testing.T, newCache Factory) {
+	t.Helper()
+
+	t.Run("single element cache", func(t *testing.T) {
+		suite.Run(t, &SingleItemCacheSuite{CacheTestHelper{NewCache: newCache}})
+	})
+	t.Run("multi element cache", func(t *testing.T) {
+		suite.Run(t, &MultiItemCacheSuite{CacheTestHelper{NewCache: newCache}})
+	})
+	t.Run("eviction", func(t *testing.T) {
+		suite.Run(t, &CacheEvictionSuite{CacheTestHelper{NewCache: newCache}})
+	})
+}
+
+// CacheTestHelper is the base of the conformance suites holding the cache under test.
+type CacheTestHelper struct {
+	suite.Suite
+	// NewCache is the factory of the caches under test.
+	NewCache Factory
+
+	cache Cache
+}
+
+func (s *CacheTestHelper) isNotInCache(k string) {
+	v, ok := s.cache.Get(k)
+	s.False(ok)
+	s.Zero(v)
+}
+
+func (s *CacheTestHelper) isInCache(k string, val int) {
+	v, ok := s.cache.Get(k)
+	s.True(ok)
+	s.Equal(val, v)
+}
+
+func (s *CacheTestHelper) setExisting(k string, val int) {
+	wasInCache := s.cache.Set(k, val)
+	s.True(wasInCache)
+}
+
+func (s *CacheTestHelper) setNew(k string, val int) {
+	wasInCache := s.cache.Set(k, val)
+	s.False(wasInCache)
+}
+
+// SingleItemCacheSuite checks a cache with the capacity of 1.
+type SingleItemCacheSuite struct {
+	CacheTestHelper
+}
+
+func (s *SingleItemCacheSuite) SetupTest() {
+	s.cache = s.NewCache(1)
+}
+
+func (s *SingleItemCacheSuite) TestSetToEmpty() {
+	s.setNew("key1", 100)
+}
+
+func (s *SingleItemCacheSuite) TestSetWithUpdate() {
+	s.setNew("key1", 100)
+	s.setExisting("key1", 200)
+}
+
+func (s *SingleItemCacheSuite) TestSetToFull() {
+	s.setNew("key1", 100)
+	s.setNew("key2", 200)
+}
+
+func (s *SingleItemCacheSuite) TestGetFromEmpty() {
+	s.isNotInCache("key1")
+}
+
+func (s *SingleItemCacheSuite) TestGetFromFilled() {
+	s.cache.Set("key1", 100)
+	s.isInCache("key1", 100)
+}
+
+func (s *SingleItemCacheSuite) TestGetNonExistent() {
+	s.cache.Set("key1", 100)
+	s.isNotInCache("key2")
+}
+
+func (s *SingleItemCacheSuite) TestClearEmpty() {
+	s.cache.Clear()
+	s.isNotInCache("key1")
+}
+
+func (s *SingleItemCacheSuite) TestClearFilled() {
+	s.cache.Set("key1", 100)
+	s.cache.Clear()
+	s.isNotInCache("key1")
+}
+
+// MultiItemCacheSuite checks a cache with the capacity of 3.
+type MultiItemCacheSuite struct {
+	CacheTestHelper
+}
+
+func (s *MultiItemCacheSuite) SetupTest() {
+	s.cache = s.NewCache(3)
+}
+
+func (s *MultiItemCacheSuite) TestSetToEmpty() {
+	s.setNew("key1", 100)
+	s.isInCache("key1", 100)
+}
+
+func (s *MultiItemCacheSuite) TestSetToPartiallyFilled() {
+	s.cache.Set("key1", 100)
+	s.setNew("key2", 200)
+	s.isInCache("key1", 100)
+	s.isInCache("key2", 200)
+}
+
+func (s *MultiItemCacheSuite) TestSetWithUpdate() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+
+	s.setExisting("key1", 101)
+	s.setExisting("key2", 201)
+
+	s.isInCache("key1", 101)
+	s.isInCache("key2", 201)
+}
+
+func (s *MultiItemCacheSuite) TestGetFromEmpty() {
+	s.isNotInCache("key1")
+}
+
+func (s *MultiItemCacheSuite) TestGetFromPartiallyFilled() {
+	s.cache.Set("key1", 100)
+	s.isInCache("key1", 100)
+	s.isNotInCache("key2")
+}
+
+func (s *MultiItemCacheSuite) TestGetFromFull() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Set("key3", 300)
+
+	s.isInCache("key1", 100)
+	s.isInCache("key2", 200)
+	s.isInCache("key3", 300)
+}
+
+func (s *MultiItemCacheSuite) TestGetNonExistent() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Set("key3", 300)
+	s.isNotInCache("key4")
+}
+
+func (s *MultiItemCacheSuite) TestClearEmptyCache() {
+	s.cache.Clear()
+	s.isNotInCache("key1")
+}
+
+func (s *MultiItemCacheSuite) TestClearPartiallyFilled() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+
+	s.cache.Clear()
+
+	s.isNotInCache("key1")
+	s.isNotInCache("key2")
+}
+
+func (s *MultiItemCacheSuite) TestClearFull() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Set("key3", 300)
+
+	s.cache.Clear()
+
+	s.isNotInCache("key1")
+	s.isNotInCache("key2")
+	s.isNotInCache("key3")
+}
+
+func (s *MultiItemCacheSuite) TestSetAfterClear() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Clear()
+
+	s.setNew("key1", 101)
+	s.setNew("key3", 300)
+	s.setNew("key4", 400)
+
+	s.isInCache("key1", 101)
+	s.isInCache("key3", 300)
+	s.isInCache("key4", 400)
+}
+
+// CacheEvictionSuite checks the eviction order of a cache with the capacity of 3.
+type CacheEvictionSuite struct {
+	CacheTestHelper
+}
+
+func (s *CacheEvictionSuite) SetupTest() {
+	s.cache = s.NewCache(3)
+}
+
+func (s *CacheEvictionSuite) TestQueueSizeEviction() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Set("key3", 300)
+
+	s.setNew("key4", 400)
+
+	s.isNotInCache("key1")
+	s.isInCache("key2", 200)
+	s.isInCache("key3", 300)
+	s.isInCache("key4", 400)
+}
+
+func (s *CacheEvictionSuite) TestUnusedEviction() {
+	s.cache.Set("key1", 100) // [100 nil nil]
+	s.cache.Set("key2", 200) // [200 100 nil]
+	s.cache.Set("key3", 300) // [300 200 100]
+
+	s.cache.Get("key1") // [100 300 200]
+	s.cache.Get("key3") // [300 100 200]
+
+	s.cache.Set("key1", 101) // [101 300 200]
+	s.cache.Set("key2", 201) // [201 101 300]
+
+	s.cache.Set("key4", 400) // [400 201 101] -> key3 is evicted
+
+	s.isNotInCache("key3")
+	s.isInCache("key1", 101)
+	s.isInCache("key2", 201)
+	s.isInCache("key4", 400)
+}
+
+func (s *CacheEvictionSuite) TestEvictionOrder() {
+	s.cache.Set("key1", 100)
+	s.cache.Set("key2", 200)
+	s.cache.Set("key3", 300)
+	s.cache.Get("key2")
+	s.cache.Get("key1") // [100 200 300]
+
+	// Every new key evicts the least recently used one, so key3, key2 and key1 are evicted in order.
+	for i, evicted := range []string{"key3", "key2", "key1"} {
+		s.setNew("new"+strconv.Itoa(i), i)
+		s.isNotInCache(evicted)
+	}
+}
+
+func (s *CacheEvictionSuite) TestSingleItemCacheEviction() {
+	c := s.NewCache(1)
+	c.Set("key1", 100)
+	c.Set("key2", 200)
+
+	v, ok := c.Get("key1")
+	s.False(ok)
+	s.Zero(v)
+
+	v, ok = c.Get("key2")
+	s.True(ok)
+	s.Equal(200, v)
+}
@@ -0,0 +1,54 @@
+package lrutest_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Averlex/lru/lrutest"
+)
+
+// sliceCache is a naive LRU cache keeping the entries in a slice ordered from the most recently used one.
+type sliceCache struct {
+	capacity int
+	entries  []entry
+}
+
+type entry struct {
+	key   string
+	value int
+}
+
+func (c *sliceCache) Set(key string, value int) bool {
+	_, ok := c.Get(key)
+	if ok {
+		c.entries[0].value = value
+		return true
+	}
+
+	if len(c.entries) == c.capacity {
+		c.entries = c.entries[:len(c.entries)-1]
+	}
+	c.entries = slices.Insert(c.entries, 0, entry{key, value})
+
+	return false
+}
+
+func (c *sliceCache) Get(key string) (int, bool) {
+	i := slices.IndexFunc(c.entries, func(e entry) bool { return e.key == key })
+	if i < 0 {
+		return 0, false
+	}
+
+	e := c.entries[i]
+	c.entries = slices.Insert(slices.Delete(c.entries, i, i+1), 0, e)
+
+	return e.value, true
+}
+
+func (c *sliceCache) Clear() {
+	c.entries = nil
+}
+
+func TestRun(t *testing.T) {
+	lrutest.Run(t, func(capacity int) lrutest.Cache { return &sliceCache{capacity: capacity} })
+}
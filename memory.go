@@ -0,0 +1,176 @@
+package lru
+
+import (
+	"context"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	// heapLiveMetric is the runtime metric of the heap memory occupied by the live objects as of the last GC.
+	heapLiveMetric = "/gc/heap/live:bytes"
+	// gcCyclesMetric is the runtime metric of the number of the completed GC cycles.
+	gcCyclesMetric = "/gc/cycles/total:gc-cycles"
+
+	defaultMemoryWatchInterval = time.Second
+)
+
+// PressureLevel is a memory pressure threshold of the MemoryWatcher.
+type PressureLevel struct {
+	// Threshold is the share of the memory limit occupied by the live heap, which activates the level.
+	Threshold float64
+	// EvictFraction is the share of the cached items evicted from the tail of the queue on the level.
+	EvictFraction float64
+}
+
+// defaultPressureLevels are used if no levels are configured.
+var defaultPressureLevels = []PressureLevel{
+	{Threshold: 0.85, EvictFraction: 0.1},
+	{Threshold: 0.95, EvictFraction: 0.25},
+}
+
+// MemoryWatcherConfig configures the MemoryWatcher. Zero fields take the default values.
+type MemoryWatcherConfig struct {
+	// Limit is the soft limit of the live heap in bytes. Defaults to the limit set by debug.SetMemoryLimit.
+	// The watcher does nothing if neither of them is set.
+	Limit uint64
+	// Levels are the pressure thresholds, the highest one crossed by the live heap is applied.
+	// Defaults to evicting 10% of the items above 85% of the limit and 25% of the items above 95% of it.
+	Levels []PressureLevel
+	// Interval is the period of the checks made by Run. Defaults to 1 second.
+	Interval time.Duration
+}
+
+// PressureEvicter is implemented by the caches which can shed their items under memory pressure,
+// such as the ones created by NewCache.
+type PressureEvicter interface {
+	// EvictFraction evicts the given share of the least recently used items which are not pinned.
+	// Returns the number of the evicted items.
+	EvictFraction(fraction float64) int
+}
+
+// MemoryWatcher evicts the least recently used items from a cache when the live heap approaches
+// the memory limit. The items of the caches created by NewCache are evicted with EvictionReasonMemoryPressure
+// and are counted as Stats.MemoryEvictions. Check and Run must not be called concurrently.
+type MemoryWatcher struct {
+	cache PressureEvicter
+	cfg   MemoryWatcherConfig
+	// readHeap returns the live heap size and the number of the completed GC cycles.
+	readHeap  func() (uint64, uint64)
+	readLimit func() uint64
+	lastCycle uint64
+}
+
+// NewMemoryWatcher returns a new MemoryWatcher of the cache with the given configuration.
+func NewMemoryWatcher(c PressureEvicter, cfg MemoryWatcherConfig) *MemoryWatcher {
+	if len(cfg.Levels) == 0 {
+		cfg.Levels = defaultPressureLevels
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultMemoryWatchInterval
+	}
+
+	return &MemoryWatcher{
+		cache:     c,
+		cfg:       cfg,
+		readHeap:  readHeapLive,
+		readLimit: readMemoryLimit,
+	}
+}
+
+// Check compares the live heap to the memory limit and evicts the items according to the highest crossed level.
+// The live heap is updated by the GC only, so the items are evicted at most once per GC cycle.
+// Returns the number of the evicted items.
+func (w *MemoryWatcher) Check() int {
+	limit := w.cfg.Limit
+	if limit == 0 {
+		limit = w.readLimit()
+	}
+	if limit == 0 {
+		return 0
+	}
+
+	live, cycle := w.readHeap()
+	if cycle == w.lastCycle {
+		return 0
+	}
+
+	usage := float64(live) / float64(limit)
+	fraction := 0.0
+	for _, level := range w.cfg.Levels {
+		if usage >= level.Threshold {
+			fraction = max(fraction, level.EvictFraction)
+		}
+	}
+	if fraction <= 0 {
+		return 0
+	}
+
+	w.lastCycle = cycle
+
+	return w.cache.EvictFraction(fraction)
+}
+
+// Run checks the memory every configured interval until the context is done.
+func (w *MemoryWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// EvictFraction evicts the given share of the items from the tail of the queue, skipping the pinned ones.
+// The items are evicted with EvictionReasonMemoryPressure. Returns the number of the evicted items.
+func (c *lruCache[K, V]) EvictFraction(fraction float64) int {
+	c.lock()
+	defer c.unlock()
+
+	n := int(math.Ceil(float64(c.queue.Len()) * min(fraction, 1)))
+
+	evicted := 0
+	for ; evicted < n; evicted++ {
+		victim := c.victim()
+		if victim == nilNode {
+			break
+		}
+		c.remove(victim, EvictionReasonMemoryPressure)
+	}
+
+	c.stats.memoryEvictions.Add(uint64(evicted))
+
+	return evicted
+}
+
+// readHeapLive returns the live heap size and the number of the completed GC cycles reported by the runtime.
+func readHeapLive() (uint64, uint64) {
+	samples := []metrics.Sample{{Name: heapLiveMetric}, {Name: gcCyclesMetric}}
+	metrics.Read(samples)
+
+	var res [2]uint64
+	for i, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			res[i] = s.Value.Uint64()
+		}
+	}
+
+	return res[0], res[1]
+}
+
+// readMemoryLimit returns the limit set by debug.SetMemoryLimit, or 0 if it is not set.
+func readMemoryLimit() uint64 {
+	limit := debug.SetMemoryLimit(-1)
+	if limit <= 0 || limit == math.MaxInt64 {
+		return 0
+	}
+
+	return uint64(limit)
+}
@@ -0,0 +1,119 @@
+package lru
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryWatcher(t *testing.T) {
+	// newWatcher returns a watcher of a cache filled with 100 items, reporting the given live heap size.
+	newWatcher := func(t *testing.T, cfg MemoryWatcherConfig) (*MemoryWatcher, *lruCache[string, int], *[]evictionRecord, *uint64) {
+		t.Helper()
+
+		c, records := recordingCache(100)
+		for i := range 100 {
+			c.Set(strconv.Itoa(i), i)
+		}
+
+		w := NewMemoryWatcher(c, cfg)
+
+		live, cycle := new(uint64), uint64(0)
+		w.readHeap = func() (uint64, uint64) {
+			cycle++
+			return *live, cycle
+		}
+		w.readLimit = func() uint64 { return 0 }
+
+		return w, c, records, live
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		w, c, records, live := newWatcher(t, MemoryWatcherConfig{Limit: 1000})
+
+		*live = 800
+		require.Zero(t, w.Check())
+
+		*live = 900
+		require.Equal(t, 10, w.Check())
+		require.Equal(t, evictionRecord{"0", 0, EvictionReasonMemoryPressure}, (*records)[0])
+		require.Equal(t, evictionRecord{"9", 9, EvictionReasonMemoryPressure}, (*records)[9])
+
+		*live = 990
+		require.Equal(t, 23, w.Check())
+
+		stats := c.Stats()
+		require.Equal(t, uint64(33), stats.MemoryEvictions)
+		require.Zero(t, stats.Evictions)
+		require.Equal(t, 67, stats.Len)
+		require.Equal(t, 100, stats.Capacity)
+	})
+
+	t.Run("once per gc cycle", func(t *testing.T) {
+		w, _, _, live := newWatcher(t, MemoryWatcherConfig{Limit: 1000})
+		w.readHeap = func() (uint64, uint64) { return *live, 1 }
+
+		*live = 900
+		require.Equal(t, 10, w.Check())
+		require.Zero(t, w.Check())
+	})
+
+	t.Run("custom levels", func(t *testing.T) {
+		w, _, _, live := newWatcher(t, MemoryWatcherConfig{
+			Limit:  1000,
+			Levels: []PressureLevel{{Threshold: 0.5, EvictFraction: 2}},
+		})
+
+		*live = 500
+		require.Equal(t, 100, w.Check())
+	})
+
+	t.Run("pinned items are skipped", func(t *testing.T) {
+		w, c, _, live := newWatcher(t, MemoryWatcherConfig{Limit: 1000, Levels: []PressureLevel{{0.5, 1}}})
+		h, _ := c.Acquire("0")
+		defer h.Release()
+
+		*live = 900
+		require.Equal(t, 99, w.Check())
+		require.Equal(t, 1, c.Len())
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		w, _, _, live := newWatcher(t, MemoryWatcherConfig{})
+		*live = 900
+		require.Zero(t, w.Check(), "no limit")
+
+		w.readLimit = func() uint64 { return 1000 }
+		require.Equal(t, 10, w.Check())
+
+		require.Zero(t, readMemoryLimit(), "the limit is not set in tests")
+	})
+
+	t.Run("runtime metrics", func(t *testing.T) {
+		runtime.GC()
+		live, cycles := readHeapLive()
+		require.NotZero(t, live)
+		require.NotZero(t, cycles)
+	})
+
+	t.Run("run", func(t *testing.T) {
+		w, c, _, live := newWatcher(t, MemoryWatcherConfig{Limit: 1000, Interval: time.Millisecond})
+		*live = 900
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			w.Run(ctx)
+		}()
+
+		require.Eventually(t, func() bool { return c.Stats().MemoryEvictions > 0 }, time.Second, time.Millisecond)
+
+		cancel()
+		<-done
+	})
+}
@@ -0,0 +1,160 @@
+package lru
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// modelCache is a naive reference LRU cache keeping the entries in a slice ordered from the most recently used one.
+type modelCache struct {
+	capacity int
+	entries  []evictionRecord
+	evicted  []evictionRecord
+}
+
+func (m *modelCache) find(key string) int {
+	return slices.IndexFunc(m.entries, func(e evictionRecord) bool { return e.key == key })
+}
+
+func (m *modelCache) promote(i int) {
+	e := m.entries[i]
+	m.entries = slices.Insert(slices.Delete(m.entries, i, i+1), 0, e)
+}
+
+func (m *modelCache) evictBack(reason EvictionReason) {
+	e := m.entries[len(m.entries)-1]
+	m.entries = m.entries[:len(m.entries)-1]
+	e.reason = reason
+	m.evicted = append(m.evicted, e)
+}
+
+func (m *modelCache) Set(key string, value int) bool {
+	if i := m.find(key); i >= 0 {
+		m.entries[i].value = value
+		m.promote(i)
+		return true
+	}
+
+	if len(m.entries) == m.capacity {
+		m.evictBack(EvictionReasonCapacity)
+	}
+	m.entries = slices.Insert(m.entries, 0, evictionRecord{key: key, value: value})
+
+	return false
+}
+
+func (m *modelCache) Get(key string) (int, bool) {
+	i := m.find(key)
+	if i < 0 {
+		return 0, false
+	}
+
+	m.promote(i)
+
+	return m.entries[0].value, true
+}
+
+func (m *modelCache) Delete(key string) bool {
+	i := m.find(key)
+	if i < 0 {
+		return false
+	}
+
+	e := m.entries[i]
+	e.reason = EvictionReasonDeleted
+	m.evicted = append(m.evicted, e)
+	m.entries = slices.Delete(m.entries, i, i+1)
+
+	return true
+}
+
+func (m *modelCache) Clear() {
+	for len(m.entries) > 0 {
+		m.evictBack(EvictionReasonCleared)
+	}
+}
+
+func (m *modelCache) Resize(capacity int) int {
+	if capacity < 1 {
+		return 0
+	}
+
+	m.capacity = capacity
+	n := 0
+	for len(m.entries) > capacity {
+		m.evictBack(EvictionReasonCapacity)
+		n++
+	}
+
+	return n
+}
+
+// Operations of the model test. Every operation is encoded with two bytes: the operation and its argument.
+const (
+	opSet = iota
+	opGet
+	opDelete
+	opClear
+	opResize
+	opCount
+)
+
+// runModel applies the operations encoded in ops to both the cache and the model,
+// comparing the results, the evicted items and the lengths after every operation.
+func runModel(t *testing.T, ops []byte, opts ...Option[string, int]) {
+	t.Helper()
+
+	const capacity = 4
+	const keys = 8
+
+	c, records := recordingCache(capacity, opts...)
+	m := &modelCache{capacity: capacity, evicted: []evictionRecord{}}
+
+	seen := 0
+	for i := 0; i+1 < len(ops); i += 2 {
+		op, arg := ops[i]%opCount, int(ops[i+1])
+		key := string(rune('a' + arg%keys))
+
+		switch op {
+		case opSet:
+			require.Equal(t, m.Set(key, i), c.Set(key, i), "set %s at %d", key, i)
+		case opGet:
+			mv, mok := m.Get(key)
+			v, ok := c.Get(key)
+			require.Equal(t, mok, ok, "get %s at %d", key, i)
+			require.Equal(t, mv, v, "get %s at %d", key, i)
+		case opDelete:
+			require.Equal(t, m.Delete(key), c.Delete(key), "delete %s at %d", key, i)
+		case opClear:
+			m.Clear()
+			c.Clear()
+		case opResize:
+			// Zero capacity is included to check the invalid capacities are ignored.
+			size := arg % (2 * capacity)
+			require.Equal(t, m.Resize(size), c.Resize(size), "resize to %d at %d", size, i)
+		}
+
+		require.Equal(t, m.evicted[seen:], (*records)[seen:], "evictions at %d", i)
+		seen = len(m.evicted)
+		require.Equal(t, len(m.entries), c.Len(), "len at %d", i)
+		require.NoError(t, c.Validate(), "validate at %d", i)
+	}
+}
+
+func FuzzCacheModel(f *testing.F) {
+	f.Add([]byte{opSet, 0, opSet, 1, opGet, 0, opSet, 2})
+	f.Add([]byte{opSet, 0, opSet, 1, opSet, 2, opSet, 3, opGet, 0, opSet, 4, opDelete, 1, opSet, 5})
+	f.Add([]byte{opSet, 0, opSet, 1, opSet, 2, opResize, 2, opSet, 3, opResize, 0, opResize, 7, opClear, 0})
+	f.Add([]byte{opSet, 0, opSet, 0, opDelete, 0, opDelete, 0, opGet, 0, opClear, 0, opSet, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		t.Run("list", func(t *testing.T) {
+			runModel(t, ops)
+		})
+		t.Run("preallocated nodes", func(t *testing.T) {
+			runModel(t, ops, WithPreallocatedNodes[string, int]())
+		})
+	})
+}
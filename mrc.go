@@ -0,0 +1,277 @@
+package lru
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// defaultSampleRate is the sampling rate of the miss ratio tracking used for invalid rates.
+	defaultSampleRate = 0.01
+	// defaultMaxSampledKeys is the limit of the sampled keys used for invalid limits.
+	defaultMaxSampledKeys = 8192
+	// sampleModulus is the modulus of the key hashes compared to the sampling threshold.
+	sampleModulus = 1 << 24
+	// timestampsPerKey is the number of the timestamps per sampled key issued between the compactions.
+	timestampsPerKey = 4
+)
+
+// MissRatio is an estimated miss ratio of Get for a cache capacity.
+type MissRatio struct {
+	Capacity  int
+	MissRatio float64
+}
+
+// WithMissRatioTracking makes the cache estimate its miss ratio curve, i.e. the miss ratio it would have
+// with other capacities, using spatially hashed sampling of the reuse distances (SHARDS). Only the accesses
+// of the keys sampled with the given rate are tracked, so the overhead is bounded by the rate. The reuse distances
+// are computed in logarithmic time after the cache mutex is released.
+// Up to maxSampledKeys sampled keys are tracked, longer reuse distances are counted as misses.
+// Rates outside of (0, 1] and non-positive limits are replaced by the defaults of 0.01 and 8192.
+// The estimates are reported by EstimateMissRatios.
+func WithMissRatioTracking[K comparable, V any](rate float64, maxSampledKeys int) Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		if rate <= 0 || rate > 1 {
+			rate = defaultSampleRate
+		}
+		if maxSampledKeys < 1 {
+			maxSampledKeys = defaultMaxSampledKeys
+		}
+
+		seed := maphash.MakeSeed()
+		c.mrc = &missRatioTracker[K]{
+			hash:      func(key K) uint64 { return maphash.Comparable(seed, key) },
+			rate:      rate,
+			threshold: uint64(rate * sampleModulus),
+			maxKeys:   maxSampledKeys,
+			last:      make(map[K]int),
+			owners:    make([]K, maxSampledKeys*timestampsPerKey),
+			tree:      make([]int32, maxSampledKeys*timestampsPerKey+1),
+			hist:      make([]uint64, maxSampledKeys),
+		}
+	}
+}
+
+// MissRatioEstimator is implemented by the caches estimating their miss ratio curve, such as the ones
+// created by NewCache.
+type MissRatioEstimator interface {
+	EstimateMissRatios(scales ...float64) ([]MissRatio, bool)
+}
+
+// EstimateMissRatios returns the estimated miss ratios of the cache for its capacity multiplied by each of
+// the scales, e.g. 0.5, 2 and 4. Returns false if the cache does not track its miss ratio curve,
+// see WithMissRatioTracking, or no sampled Get calls were made yet.
+func (c *lruCache[K, V]) EstimateMissRatios(scales ...float64) ([]MissRatio, bool) {
+	if c.mrc == nil {
+		return nil, false
+	}
+
+	c.lock()
+	capacity := c.capacity
+	c.unlock()
+
+	res := make([]MissRatio, 0, len(scales))
+	for _, scale := range scales {
+		size := max(1, int(math.Round(float64(capacity)*scale)))
+		ratio, ok := c.mrc.missRatio(size)
+		if !ok {
+			return nil, false
+		}
+		res = append(res, MissRatio{Capacity: size, MissRatio: ratio})
+	}
+
+	return res, true
+}
+
+// missRatioTracker keeps the histogram of the reuse distances of the sampled Get calls. Following SHARDS,
+// every sampled access gets a timestamp, and the latest access of every key is marked in a Fenwick tree,
+// so the reuse distance is the number of the marks after the previous access of the key.
+// The timestamps are renumbered when they run out. It is safe for concurrent use.
+type missRatioTracker[K comparable] struct {
+	// hash spreads the keys for the sampling. It is seeded randomly for every tracker.
+	hash      func(key K) uint64
+	rate      float64
+	threshold uint64
+	maxKeys   int
+
+	// total counts all the reads, including the ones of the keys which are not sampled.
+	total atomic.Uint64
+
+	mu sync.Mutex
+	// last is the timestamp of the latest access of every tracked key.
+	last map[K]int
+	// owners are the keys by the timestamps of their latest accesses.
+	owners []K
+	// tree is a Fenwick tree over the timestamps marking the latest accesses of the keys.
+	tree []int32
+	// now is the next timestamp.
+	now int
+	// hist counts the reads by the number of the distinct sampled keys accessed since the previous access.
+	hist  []uint64
+	reads uint64
+}
+
+// sampledAccess is an access of a sampled key made under the cache mutex, which is recorded after its release.
+type sampledAccess[K comparable] struct {
+	key  K
+	read bool
+}
+
+// sampled reports whether the accesses of the key are tracked.
+func (t *missRatioTracker[K]) sampled(key K) bool {
+	return t.hash(key)%sampleModulus < t.threshold
+}
+
+// access records the access of the key if it is sampled.
+func (t *missRatioTracker[K]) access(key K, read bool) {
+	if read {
+		t.total.Add(1)
+	}
+	if t.sampled(key) {
+		t.record(key, read)
+	}
+}
+
+// record records the access of the sampled key. Reads add their reuse distance to the histogram,
+// writes only update the recency of the key.
+func (t *missRatioTracker[K]) record(key K, read bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.now == len(t.owners) {
+		t.compact()
+	}
+
+	ts, ok := t.last[key]
+	if read {
+		t.reads++
+		if ok {
+			// The marks after the previous access are the keys accessed since then.
+			t.hist[len(t.last)-t.prefix(ts)]++
+		}
+	}
+
+	var zeroKey K
+	if ok {
+		t.mark(ts, -1)
+		t.owners[ts] = zeroKey
+	}
+
+	t.last[key] = t.now
+	t.owners[t.now] = key
+	t.mark(t.now, 1)
+	t.now++
+
+	if !ok && len(t.last) > t.maxKeys {
+		oldest := t.first()
+		delete(t.last, t.owners[oldest])
+		t.mark(oldest, -1)
+		t.owners[oldest] = zeroKey
+	}
+}
+
+// mark adds delta to the mark of the timestamp. The caller must hold the mutex.
+func (t *missRatioTracker[K]) mark(ts int, delta int32) {
+	for i := ts + 1; i < len(t.tree); i += i & -i {
+		t.tree[i] += delta
+	}
+}
+
+// prefix returns the number of the marks up to the timestamp inclusive. The caller must hold the mutex.
+func (t *missRatioTracker[K]) prefix(ts int) int {
+	n := 0
+	for i := ts + 1; i > 0; i -= i & -i {
+		n += int(t.tree[i])
+	}
+
+	return n
+}
+
+// first returns the earliest marked timestamp. There must be at least one. The caller must hold the mutex.
+func (t *missRatioTracker[K]) first() int {
+	pos := 0
+	for step := 1 << bits.Len(uint(len(t.tree)-1)); step > 0; step >>= 1 {
+		if next := pos + step; next < len(t.tree) && t.tree[next] == 0 {
+			pos = next
+		}
+	}
+
+	return pos
+}
+
+// compact renumbers the latest accesses of the keys from zero keeping their order. The caller must hold the mutex.
+func (t *missRatioTracker[K]) compact() {
+	var zeroKey K
+	n := 0
+
+	for ts := range t.now {
+		key := t.owners[ts]
+		t.owners[ts] = zeroKey
+		if last, ok := t.last[key]; !ok || last != ts {
+			continue
+		}
+
+		t.owners[n] = key
+		t.last[key] = n
+		n++
+	}
+
+	clear(t.tree)
+	for ts := range n {
+		t.mark(ts, 1)
+	}
+	t.now = n
+}
+
+// missRatio returns the estimated miss ratio for the capacity. Returns false if there are no sampled reads.
+// A read hits the cache of the capacity if its reuse distance scaled by the sampling rate is within it.
+func (t *missRatioTracker[K]) missRatio(capacity int) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.reads == 0 {
+		return 0, false
+	}
+
+	limit := min(len(t.hist), int(math.Ceil(float64(capacity)*t.rate)))
+
+	var hits uint64
+	for _, n := range t.hist[:limit] {
+		hits += n
+	}
+
+	// The hot keys make the number of the sampled reads deviate from the expected one. Following SHARDS-adj,
+	// the difference is attributed to the shortest reuse distance, which is within any capacity.
+	expected := float64(t.total.Load()) * t.rate
+	adjusted := float64(hits) + expected - float64(t.reads)
+	ratio := 1 - adjusted/expected
+
+	return min(max(ratio, 0), 1), true
+}
+
+// trackRead schedules recording of the read of the key if the miss ratio is tracked. The caller must hold the mutex.
+func (c *lruCache[K, V]) trackRead(key K) {
+	if c.mrc != nil {
+		c.mrc.total.Add(1)
+		c.trackSampled(key, true)
+	}
+}
+
+// trackWrite schedules recording of the write of the key if the miss ratio is tracked.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) trackWrite(key K) {
+	if c.mrc != nil {
+		c.trackSampled(key, false)
+	}
+}
+
+// trackSampled schedules recording of the access of a sampled key after the mutex is released.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) trackSampled(key K, read bool) {
+	if c.mrc.sampled(key) {
+		c.sampledAccesses = append(c.sampledAccesses, sampledAccess[K]{key, read})
+	}
+}
@@ -0,0 +1,149 @@
+package lru
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// zipfKeys returns n keys of a skewed distribution over the given number of distinct keys.
+func zipfKeys(n, distinct int) []string {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	zipf := rand.NewZipf(rnd, 1.1, 1, uint64(distinct-1))
+
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.FormatUint(zipf.Uint64(), 10)
+	}
+
+	return keys
+}
+
+// missRatio returns the miss ratio of Get for the cache-aside access of the keys.
+func missRatio(c Cache[string, int], keys []string) float64 {
+	misses := 0
+	for _, key := range keys {
+		if _, ok := c.Get(key); !ok {
+			misses++
+			c.Set(key, 0)
+		}
+	}
+
+	return float64(misses) / float64(len(keys))
+}
+
+// fixedHash is a string hash with a fixed seed, making the sampled keys deterministic.
+// The FNV-1a hash is mixed with the splitmix64 finalizer to spread the similar keys.
+func fixedHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+
+	return x ^ x>>31
+}
+
+func TestMissRatioTracking(t *testing.T) {
+	keys := zipfKeys(20_000, 2000)
+	scales := []float64{0.5, 1, 2, 4}
+
+	t.Run("exact without sampling", func(t *testing.T) {
+		c := NewCache(100, WithMissRatioTracking[string, int](1, 1000))
+		actual := missRatio(c, keys)
+
+		estimates, ok := c.(MissRatioEstimator).EstimateMissRatios(scales...)
+		require.True(t, ok)
+		require.Len(t, estimates, len(scales))
+
+		for i, e := range estimates {
+			require.Equal(t, int(100*scales[i]), e.Capacity)
+			require.InDelta(t, missRatio(NewCache[string, int](e.Capacity), keys), e.MissRatio, 1e-9, "capacity %d", e.Capacity)
+		}
+		require.InDelta(t, actual, estimates[1].MissRatio, 1e-9)
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		keys := zipfKeys(200_000, 100_000)
+		c := NewCache(1000, WithMissRatioTracking[string, int](0.1, 0))
+		// The random seed makes the estimates vary noticeably depending on whether the hottest keys are sampled.
+		c.(*lruCache[string, int]).mrc.hash = fixedHash
+		missRatio(c, keys)
+
+		estimates, ok := c.(MissRatioEstimator).EstimateMissRatios(scales...)
+		require.True(t, ok)
+
+		for _, e := range estimates {
+			require.InDelta(t, missRatio(NewCache[string, int](e.Capacity), keys), e.MissRatio, 0.05, "capacity %d", e.Capacity)
+		}
+	})
+
+	t.Run("distances beyond the tracked keys are misses", func(t *testing.T) {
+		c := NewCache(2, WithMissRatioTracking[string, int](1, 2))
+		for _, key := range []string{"a", "b", "c"} {
+			c.Set(key, 0)
+		}
+		c.Get("a") // a is not tracked anymore.
+		c.Get("c")
+
+		estimates, ok := c.(MissRatioEstimator).EstimateMissRatios(1, 10)
+		require.True(t, ok)
+		require.Equal(t, []MissRatio{{2, 0.5}, {20, 0.5}}, estimates)
+	})
+
+	t.Run("read-buffered cache", func(t *testing.T) {
+		c := NewCache(10, WithReadBuffers[string, int](), WithMissRatioTracking[string, int](1, 0))
+		c.Set("a", 0)
+		c.Get("a")
+		c.Get("b")
+
+		estimates, ok := c.(MissRatioEstimator).EstimateMissRatios(1)
+		require.True(t, ok)
+		require.Equal(t, []MissRatio{{10, 0.5}}, estimates)
+	})
+
+	t.Run("not tracked", func(t *testing.T) {
+		_, ok := NewCache[string, int](1).(MissRatioEstimator).EstimateMissRatios(1)
+		require.False(t, ok)
+
+		c := NewCache(1, WithMissRatioTracking[string, int](1, 0))
+		_, ok = c.(MissRatioEstimator).EstimateMissRatios(1)
+		require.False(t, ok, "no reads")
+	})
+
+	t.Run("reuse distances match the LRU stack", func(t *testing.T) {
+		c := NewCache(10, WithMissRatioTracking[int, int](1, 8)).(*lruCache[int, int])
+		rnd := rand.New(rand.NewPCG(3, 4))
+
+		var stack []int // The most recent key is the last one.
+		want := make([]uint64, 8)
+		for range 10_000 {
+			key, read := rnd.IntN(12), rnd.IntN(4) > 0
+
+			pos := -1
+			for i, k := range stack {
+				if k == key {
+					pos = i
+				}
+			}
+			if read && pos >= 0 {
+				want[len(stack)-1-pos]++
+			}
+			if pos >= 0 {
+				stack = append(stack[:pos], stack[pos+1:]...)
+			}
+			stack = append(stack, key)
+			if len(stack) > 8 {
+				stack = stack[1:]
+			}
+
+			c.mrc.access(key, read)
+		}
+
+		require.Equal(t, want, c.mrc.hist)
+	})
+}
@@ -0,0 +1,22 @@
+package lru
+
+// Option configures the cache created by NewCache.
+type Option[K comparable, V any] func(*lruCache[K, V])
+
+// WithEvictionCallback sets the function called for every item removed from the cache along with
+// the reason of the removal. The callbacks are called after the cache mutex is released,
+// so it is safe to call the cache methods from them.
+func WithEvictionCallback[K comparable, V any](fn func(key K, value V, reason EvictionReason)) Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		c.onEvict = fn
+	}
+}
+
+// WithPreallocatedNodes makes the cache preallocate the nodes of its queue for the whole capacity in a single slice.
+// By default the nodes are allocated in chunks as the cache grows. It trades the memory of the unused nodes
+// for no allocations while the cache fills up.
+func WithPreallocatedNodes[K comparable, V any]() Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		c.preallocate = true
+	}
+}
@@ -0,0 +1,121 @@
+package lru
+
+// Handle is a reference to a cache entry pinned by Acquire. The entry is not evicted while it is pinned.
+type Handle[V any] interface {
+	// Value returns the value of the entry at the time it was acquired.
+	Value() V
+	// Release unpins the entry. Subsequent calls are no-ops.
+	Release()
+}
+
+// pinState tracks the handles of a pinned entry. The entry removed from the cache while it is pinned
+// keeps its value and the removal reason here until the last handle is released.
+type pinState[V any] struct {
+	count   int
+	removed bool
+	value   V
+	reason  EvictionReason
+	// stale keeps the values replaced while the entry is pinned, they are closed on the last release.
+	stale []V
+}
+
+// handle is the Handle returned by lruCache.Acquire.
+type handle[K comparable, V any] struct {
+	c        *lruCache[K, V]
+	key      K
+	value    V
+	pin      *pinState[V]
+	released bool
+}
+
+// Acquire returns a handle pinning the entry of the key if it exists in the cache, also moves the accessed item
+// to the front of the queue. Pinned entries are skipped by the eviction, so the cache may temporarily exceed
+// its capacity. Entries deleted while pinned are removed from the cache right away, but their eviction callback
+// is deferred until the last handle is released. Otherwise, returns nil and false.
+func (c *lruCache[K, V]) Acquire(key K) (Handle[V], bool) {
+	c.lock()
+	defer c.unlock()
+
+	i, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.queue.moveToFront(i)
+
+	e := c.queue.entry(i)
+	if e.pin == nil {
+		e.pin = &pinState[V]{}
+		c.pinned++
+	}
+	e.pin.count++
+
+	return &handle[K, V]{c: c, key: key, value: e.value, pin: e.pin}, true
+}
+
+// Value returns the value of the entry at the time it was acquired.
+func (h *handle[K, V]) Value() V {
+	return h.value
+}
+
+// Release unpins the entry. The last release of a removed entry calls its eviction callback,
+// the last release of a cached one lets the cache evict the items exceeding its capacity.
+func (h *handle[K, V]) Release() {
+	c := h.c
+
+	c.lock()
+	defer c.unlock()
+
+	if h.released {
+		return
+	}
+	h.released = true
+
+	pin := h.pin
+	pin.count--
+	if pin.count > 0 {
+		return
+	}
+	c.pinned--
+
+	for _, v := range pin.stale {
+		c.discard(v)
+	}
+
+	if pin.removed {
+		c.evict(h.key, pin.value, pin.reason)
+		return
+	}
+
+	if i, ok := c.items[h.key]; ok && c.queue.entry(i).pin == pin {
+		c.queue.entry(i).pin = nil
+	}
+
+	c.trim()
+}
+
+// victim returns the node index of the least recently used item which is not pinned,
+// or nilNode if all the items are pinned. The caller must hold the mutex.
+func (c *lruCache[K, V]) victim() int32 {
+	i := c.queue.Back()
+	for i != nilNode && c.queue.entry(i).pin != nil {
+		i = c.queue.prev(i)
+	}
+
+	return i
+}
+
+// detach defers the eviction callback of the pinned item removed from the cache until its last release.
+// Returns false if the item is not pinned. The caller must hold the mutex.
+func (c *lruCache[K, V]) detach(item *cacheEntry[K, V], reason EvictionReason) bool {
+	pin := item.pin
+	if pin == nil {
+		return false
+	}
+
+	pin.removed = true
+	pin.value = item.value
+	pin.reason = reason
+
+	return true
+}
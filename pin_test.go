@@ -0,0 +1,126 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Run("missing key", func(t *testing.T) {
+		c := NewCache[string, int](1)
+
+		h, ok := c.Acquire("key1")
+		require.False(t, ok)
+		require.Nil(t, h)
+	})
+
+	t.Run("pinned entries are not evicted", func(t *testing.T) {
+		c, records := recordingCache(2)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+
+		h, ok := c.Acquire("key1")
+		require.True(t, ok)
+		require.Equal(t, 100, h.Value())
+
+		c.Get("key2")
+		c.Set("key3", 300) // key1 is the least recently used one, but it is pinned.
+		require.Equal(t, []evictionRecord{{"key2", 200, EvictionReasonCapacity}}, *records)
+
+		v, ok := c.Get("key1")
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+
+		h.Release()
+		h.Release()
+		v, ok = c.Get("key1")
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+	})
+
+	t.Run("the cache exceeds its capacity while all the entries are pinned", func(t *testing.T) {
+		c, records := recordingCache(1)
+		c.Set("key1", 100)
+
+		h, _ := c.Acquire("key1")
+		c.Set("key2", 200)
+		require.Empty(t, *records)
+		require.Len(t, c.GetMany([]string{"key1", "key2"}), 2)
+
+		// key2 is accessed after key1, so key1 is the one evicted on release.
+		h.Release()
+		require.Equal(t, []evictionRecord{{"key1", 100, EvictionReasonCapacity}}, *records)
+		require.Equal(t, map[string]int{"key2": 200}, c.GetMany([]string{"key1", "key2"}))
+	})
+
+	t.Run("eviction callback of removed entries is deferred until the last release", func(t *testing.T) {
+		c, records := recordingCache(5)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+
+		h1, _ := c.Acquire("key1")
+		h2, _ := c.Acquire("key1")
+		h3, _ := c.Acquire("key2")
+
+		require.True(t, c.Delete("key1"))
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+		c.Clear()
+		require.Empty(t, *records)
+
+		h1.Release()
+		require.Empty(t, *records)
+		h2.Release()
+		h3.Release()
+		require.Equal(t, []evictionRecord{
+			{"key1", 100, EvictionReasonDeleted},
+			{"key2", 200, EvictionReasonCleared},
+		}, *records)
+	})
+
+	t.Run("handle keeps the acquired value", func(t *testing.T) {
+		c, records := recordingCache(5)
+		c.Set("key1", 100)
+
+		h, _ := c.Acquire("key1")
+		c.Set("key1", 101)
+		c.Delete("key1")
+		c.Set("key1", 102)
+		require.Equal(t, 100, h.Value())
+
+		h.Release()
+		require.Equal(t, []evictionRecord{{"key1", 101, EvictionReasonDeleted}}, *records)
+
+		// The release of the removed entry does not unpin the new one.
+		h, _ = c.Acquire("key1")
+		c.Delete("key1")
+		h.Release()
+		require.Equal(t, evictionRecord{"key1", 102, EvictionReasonDeleted}, (*records)[1])
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		c := NewCache[int, int](4)
+		wg := &sync.WaitGroup{}
+		wg.Add(8)
+
+		for g := range 8 {
+			go func() {
+				defer wg.Done()
+				for i := range 1000 {
+					c.Set(i%8, g)
+					if h, ok := c.Acquire((i + g) % 8); ok {
+						c.Set(i%8+8, g)
+						h.Release()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		lc := c.(*lruCache[int, int])
+		require.Zero(t, lc.pinned)
+		require.LessOrEqual(t, lc.queue.Len(), 4)
+	})
+}
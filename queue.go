@@ -0,0 +1,226 @@
+package lru
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// nilNode is the index of a missing node of the entry queue.
+	nilNode int32 = -1
+	// maxChunkSize limits the chunks of the entry queues which are not preallocated.
+	maxChunkSize = 1024
+)
+
+// entryQueue is the recency queue of the cache entries. The nodes are stored in chunks of contiguous slices
+// and linked with int32 indices instead of pointers, so they are neither separate heap objects nor pointers
+// for the GC to scan. Removed nodes are kept in a free list linked the same way and reused by the next insertions.
+// The chunks are allocated when the previous ones are exhausted, growing the queue without copying the nodes.
+type entryQueue[K comparable, V any] struct {
+	chunks    [][]entryNode[K, V]
+	chunkBits uint
+	// preallocated queues keep their first chunk when they are reset.
+	preallocated bool
+	// used is the number of the nodes taken from the chunks, including the free ones.
+	used  int32
+	free  int32
+	front int32
+	back  int32
+	len   int
+}
+
+// entryNode is a node of the entry queue.
+type entryNode[K comparable, V any] struct {
+	entry cacheEntry[K, V]
+	prev  int32
+	next  int32
+}
+
+// newEntryQueue returns an empty queue allocating its nodes in chunks of the given size rounded up to a power of 2.
+// A preallocated queue allocates its first chunk right away.
+func newEntryQueue[K comparable, V any](chunkSize int, preallocated bool) *entryQueue[K, V] {
+	q := &entryQueue[K, V]{
+		chunkBits:    uint(bits.Len(uint(max(chunkSize, 1) - 1))),
+		preallocated: preallocated,
+	}
+	q.reset()
+
+	return q
+}
+
+// node returns the node of the index.
+func (q *entryQueue[K, V]) node(i int32) *entryNode[K, V] {
+	return &q.chunks[i>>q.chunkBits][i&(1<<q.chunkBits-1)]
+}
+
+// entry returns the entry of the node of the index.
+func (q *entryQueue[K, V]) entry(i int32) *cacheEntry[K, V] {
+	return &q.node(i).entry
+}
+
+// Len returns the number of the entries in the queue.
+func (q *entryQueue[K, V]) Len() int {
+	return q.len
+}
+
+// Front returns the index of the most recently used entry, or nilNode if the queue is empty.
+func (q *entryQueue[K, V]) Front() int32 {
+	return q.front
+}
+
+// Back returns the index of the least recently used entry, or nilNode if the queue is empty.
+func (q *entryQueue[K, V]) Back() int32 {
+	return q.back
+}
+
+// next returns the index of the entry following the given one towards the back, or nilNode.
+func (q *entryQueue[K, V]) next(i int32) int32 {
+	return q.node(i).next
+}
+
+// prev returns the index of the entry preceding the given one towards the front, or nilNode.
+func (q *entryQueue[K, V]) prev(i int32) int32 {
+	return q.node(i).prev
+}
+
+// pushFront adds the entry to the front of the queue, reusing a free node if there is any.
+// Returns the index of its node.
+func (q *entryQueue[K, V]) pushFront(entry cacheEntry[K, V]) int32 {
+	i := q.free
+	if i != nilNode {
+		q.free = q.node(i).next
+	} else {
+		if int(q.used) == len(q.chunks)<<q.chunkBits {
+			q.chunks = append(q.chunks, make([]entryNode[K, V], 1<<q.chunkBits))
+		}
+		i = q.used
+		q.used++
+	}
+
+	q.node(i).entry = entry
+	q.linkFront(i)
+
+	return i
+}
+
+// moveToFront moves the entry of the index to the front of the queue.
+func (q *entryQueue[K, V]) moveToFront(i int32) {
+	if i == q.front {
+		return
+	}
+
+	q.unlink(i)
+	q.linkFront(i)
+}
+
+// remove deletes the entry of the index from the queue and returns it. The node is zeroed, so it does not keep
+// the entry alive, and put to the free list, so the index must not be used after the removal.
+func (q *entryQueue[K, V]) remove(i int32) cacheEntry[K, V] {
+	q.unlink(i)
+
+	n := q.node(i)
+	entry := n.entry
+	*n = entryNode[K, V]{prev: nilNode, next: q.free}
+	q.free = i
+
+	return entry
+}
+
+// reset removes all the entries from the queue. The chunks are released, except for the first one
+// of a preallocated queue, which is zeroed to be reused.
+func (q *entryQueue[K, V]) reset() {
+	switch {
+	case q.preallocated && len(q.chunks) > 0:
+		clear(q.chunks[0])
+		q.chunks = q.chunks[:1]
+	case q.preallocated:
+		q.chunks = [][]entryNode[K, V]{make([]entryNode[K, V], 1<<q.chunkBits)}
+	default:
+		q.chunks = nil
+	}
+
+	q.used = 0
+	q.free = nilNode
+	q.front = nilNode
+	q.back = nilNode
+	q.len = 0
+}
+
+// linkFront links the detached node of the index to the front of the queue.
+func (q *entryQueue[K, V]) linkFront(i int32) {
+	n := q.node(i)
+	n.prev = nilNode
+	n.next = q.front
+
+	if q.front == nilNode {
+		q.back = i
+	} else {
+		q.node(q.front).prev = i
+	}
+	q.front = i
+	q.len++
+}
+
+// unlink detaches the node of the index from the queue.
+func (q *entryQueue[K, V]) unlink(i int32) {
+	n := q.node(i)
+
+	if n.prev == nilNode {
+		q.front = n.next
+	} else {
+		q.node(n.prev).next = n.next
+	}
+	if n.next == nilNode {
+		q.back = n.prev
+	} else {
+		q.node(n.next).prev = n.prev
+	}
+
+	n.prev = nilNode
+	n.next = nilNode
+	q.len--
+}
+
+// Validate checks the structure of the queue the same way as List.Validate does: the links of the neighbouring
+// nodes must agree, the walks in both directions must end at the ends of the queue and visit Len nodes.
+// Returns an error wrapping ErrCorrupted for the first inconsistency found.
+func (q *entryQueue[K, V]) Validate() error {
+	if (q.front == nilNode) != (q.back == nilNode) {
+		return fmt.Errorf("%w: only one of the ends is set", ErrCorrupted)
+	}
+
+	n := 0
+	prev := nilNode
+	for i := q.front; i != nilNode; prev, i = i, q.next(i) {
+		if i < 0 || i >= q.used {
+			return fmt.Errorf("%w: node %d is out of the chunks", ErrCorrupted, i)
+		}
+		if n++; n > q.len {
+			return fmt.Errorf("%w: more than %d items walking forward", ErrCorrupted, q.len)
+		}
+		if q.prev(i) != prev {
+			return fmt.Errorf("%w: item %d does not link back to its predecessor", ErrCorrupted, n)
+		}
+	}
+	if prev != q.back {
+		return fmt.Errorf("%w: forward walk does not end at the back", ErrCorrupted)
+	}
+	if n != q.len {
+		return fmt.Errorf("%w: %d items walking forward, length is %d", ErrCorrupted, n, q.len)
+	}
+
+	n = 0
+	for i := q.back; i != nilNode; i = q.prev(i) {
+		if i < 0 || i >= q.used {
+			return fmt.Errorf("%w: node %d is out of the chunks", ErrCorrupted, i)
+		}
+		if n++; n > q.len {
+			return fmt.Errorf("%w: more than %d items walking backward", ErrCorrupted, q.len)
+		}
+	}
+	if n != q.len {
+		return fmt.Errorf("%w: %d items walking backward, length is %d", ErrCorrupted, n, q.len)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/Averlex/lru/lrutest"
+	"github.com/stretchr/testify/require"
+)
+
+// queueKeys returns the keys of the queue from the front to the back.
+func queueKeys(q *entryQueue[int, int]) []int {
+	keys := make([]int, 0, q.Len())
+	for i := q.Front(); i != nilNode; i = q.next(i) {
+		keys = append(keys, q.entry(i).key)
+	}
+
+	return keys
+}
+
+func TestEntryQueue(t *testing.T) {
+	t.Run("empty queue", func(t *testing.T) {
+		q := newEntryQueue[int, int](4, false)
+
+		require.Zero(t, q.Len())
+		require.Equal(t, nilNode, q.Front())
+		require.Equal(t, nilNode, q.Back())
+		require.NoError(t, q.Validate())
+	})
+
+	t.Run("push, move and remove", func(t *testing.T) {
+		q := newEntryQueue[int, int](4, false)
+		idx := make([]int32, 3)
+		for i := range idx {
+			idx[i] = q.pushFront(cacheEntry[int, int]{key: i, value: i * 10})
+		}
+		require.Equal(t, []int{2, 1, 0}, queueKeys(q))
+
+		q.moveToFront(idx[0])
+		require.Equal(t, []int{0, 2, 1}, queueKeys(q))
+		q.moveToFront(idx[0])
+		require.Equal(t, []int{0, 2, 1}, queueKeys(q))
+
+		require.Equal(t, cacheEntry[int, int]{key: 2, value: 20}, q.remove(idx[2]))
+		require.Equal(t, []int{0, 1}, queueKeys(q))
+		require.Equal(t, idx[0], q.Front())
+		require.Equal(t, idx[1], q.Back())
+		require.NoError(t, q.Validate())
+	})
+
+	t.Run("removed nodes are reused", func(t *testing.T) {
+		q := newEntryQueue[*int, *int](2, false)
+		value := new(int)
+		i := q.pushFront(cacheEntry[*int, *int]{key: value, value: value})
+		q.pushFront(cacheEntry[*int, *int]{key: value, value: value})
+
+		q.remove(i)
+		require.Equal(t, cacheEntry[*int, *int]{}, *q.entry(i), "the value is kept alive by the free node")
+		require.Equal(t, i, q.pushFront(cacheEntry[*int, *int]{value: value}))
+
+		allocs := testing.AllocsPerRun(100, func() {
+			q.remove(q.pushFront(cacheEntry[*int, *int]{value: value}))
+		})
+		require.Zero(t, allocs)
+	})
+
+	t.Run("the queue grows in chunks", func(t *testing.T) {
+		q := newEntryQueue[int, int](3, false)
+		for i := range 10 {
+			q.pushFront(cacheEntry[int, int]{key: i})
+		}
+
+		require.Len(t, q.chunks, 3, "the chunk size is rounded up to 4")
+		require.Equal(t, []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}, queueKeys(q))
+		require.NoError(t, q.Validate())
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		q := newEntryQueue[int, int](4, false)
+		q.pushFront(cacheEntry[int, int]{key: 1})
+		q.reset()
+		require.Zero(t, q.Len())
+		require.Empty(t, q.chunks)
+
+		p := newEntryQueue[int, int](4, true)
+		require.Len(t, p.chunks, 1, "the first chunk is preallocated")
+		for i := range 6 {
+			p.pushFront(cacheEntry[int, int]{key: i})
+		}
+		p.reset()
+		require.Zero(t, p.Len())
+		require.Len(t, p.chunks, 1, "the first chunk is kept")
+		require.Equal(t, make([]entryNode[int, int], 4), p.chunks[0])
+		require.NoError(t, p.Validate())
+	})
+
+	t.Run("corruptions are detected", func(t *testing.T) {
+		corruptions := []struct {
+			name    string
+			corrupt func(q *entryQueue[int, int])
+		}{
+			{"broken next link", func(q *entryQueue[int, int]) { q.node(q.Front()).next = nilNode }},
+			{"broken prev link", func(q *entryQueue[int, int]) { q.node(q.Back()).prev = q.Back() }},
+			{"out of range link", func(q *entryQueue[int, int]) { q.node(q.Front()).next = 100 }},
+			{"wrong length", func(q *entryQueue[int, int]) { q.len++ }},
+			{"only one end", func(q *entryQueue[int, int]) { q.back = nilNode }},
+		}
+
+		for _, tc := range corruptions {
+			t.Run(tc.name, func(t *testing.T) {
+				q := newEntryQueue[int, int](2, false)
+				for i := range 3 {
+					q.pushFront(cacheEntry[int, int]{key: i})
+				}
+				tc.corrupt(q)
+				require.ErrorIs(t, q.Validate(), ErrCorrupted)
+			})
+		}
+	})
+}
+
+func TestCacheWithPreallocatedNodes(t *testing.T) {
+	lrutest.Run(t, func(capacity int) lrutest.Cache {
+		return NewCache(capacity, WithPreallocatedNodes[string, int]())
+	})
+
+	c, records := recordingCache(2, WithPreallocatedNodes[string, int]())
+
+	c.Set("key1", 100)
+	c.Set("key2", 200)
+	c.Set("key3", 300)
+	c.Delete("key2")
+	c.Set("key4", 400)
+
+	require.Equal(t, []evictionRecord{
+		{"key1", 100, EvictionReasonCapacity},
+		{"key2", 200, EvictionReasonDeleted},
+	}, *records)
+	require.Equal(t, map[string]int{"key3": 300, "key4": 400}, c.GetMany([]string{"key1", "key2", "key3", "key4"}))
+
+	c.Clear()
+	_, ok := c.Get("key3")
+	require.False(t, ok)
+}
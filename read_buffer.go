@@ -0,0 +1,153 @@
+package lru
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// readBufferSize is the number of the accesses a read buffer stripe holds before it is drained.
+	readBufferSize = 64
+	// cacheLineSize is used to pad the stripes, so they do not share the CPU cache lines.
+	cacheLineSize = 64
+)
+
+// readBuffers lets Get hits avoid the cache mutex. The hits are looked up in a concurrent read index
+// and recorded into striped lossy buffers, which are applied to the queue in batches by the goroutine
+// which acquires the cache mutex next.
+type readBuffers[K comparable, V any] struct {
+	// index mirrors the cache items. It is modified under the cache mutex only.
+	index   sync.Map
+	stripes []readStripe[K]
+	mask    uint32
+	// buffered is the number of the accesses in all the stripes, the mutex holders skip draining without them.
+	buffered atomic.Int64
+}
+
+// readStripe is a single read buffer. Accesses are dropped while it is busy or full.
+type readStripe[K comparable] struct {
+	mu   sync.Mutex
+	keys []K
+	// size mirrors the length of keys, so the empty stripes are skipped without locking them.
+	size atomic.Int32
+	_    [cacheLineSize]byte
+}
+
+// readEntry is an immutable snapshot of the cached value published to the read index.
+type readEntry[V any] struct {
+	value V
+}
+
+// WithReadBuffers makes Get hits skip the cache mutex. The accessed items are promoted in batches
+// when the mutex is acquired next, so the recency of the items is updated with a delay, the accesses recorded
+// to different stripes may be reordered, and some of them may be dropped under contention.
+// Designed for read-mostly workloads on many cores, where the mutex contention dominates.
+// Updates of the cached values allocate in this mode.
+func WithReadBuffers[K comparable, V any]() Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		n := 1 << bits.Len(uint(runtime.GOMAXPROCS(0)*4-1))
+
+		c.reads = &readBuffers[K, V]{
+			stripes: make([]readStripe[K], n),
+			mask:    uint32(n - 1),
+		}
+		for i := range c.reads.stripes {
+			c.reads.stripes[i].keys = make([]K, 0, readBufferSize)
+		}
+	}
+}
+
+// lock acquires the mutex and applies the buffered reads to the queue, if there are any.
+func (c *lruCache[K, V]) lock() {
+	c.mu.Lock()
+	if c.reads != nil && c.reads.buffered.Load() > 0 {
+		c.drainReads()
+	}
+}
+
+// getBuffered looks up the key in the read index without the mutex and records the access on a hit.
+func (c *lruCache[K, V]) getBuffered(key K) (V, bool) {
+	var zeroVal V
+
+	// The mutex is not held, so the access is recorded right away.
+	if c.mrc != nil {
+		c.mrc.access(key, true)
+	}
+
+	e, ok := c.reads.index.Load(key)
+	if !ok {
+		// The ghost entries are checked under the mutex, the misses usually acquire it anyway to set the value.
+		if c.ghosts != nil {
+			c.lock()
+			c.recordLookup(func() K { return key }, false)
+			c.unlock()
+		} else {
+			c.stats.misses.Add(1)
+		}
+		return zeroVal, false
+	}
+	c.stats.hits.Add(1)
+
+	stripe := &c.reads.stripes[rand.Uint32()&c.reads.mask]
+	if stripe.mu.TryLock() {
+		if len(stripe.keys) < readBufferSize {
+			stripe.keys = append(stripe.keys, key)
+			stripe.size.Store(int32(len(stripe.keys)))
+			c.reads.buffered.Add(1)
+		}
+		full := len(stripe.keys) == readBufferSize
+		stripe.mu.Unlock()
+
+		// Draining the buffers right away if nobody holds the mutex, otherwise the next holder does it.
+		if full && c.mu.TryLock() {
+			c.drainReads()
+			c.unlock()
+		}
+	}
+
+	return e.(*readEntry[V]).value, true
+}
+
+// drainReads promotes the items accessed through the read buffers. The empty stripes are skipped,
+// as well as the busy ones, their accesses are drained by the next mutex holder. The caller must hold the mutex.
+func (c *lruCache[K, V]) drainReads() {
+	if c.reads == nil {
+		return
+	}
+
+	for i := range c.reads.stripes {
+		stripe := &c.reads.stripes[i]
+
+		if stripe.size.Load() == 0 || !stripe.mu.TryLock() {
+			continue
+		}
+		for _, key := range stripe.keys {
+			// The key may have been removed since the access was recorded.
+			if i, ok := c.items[key]; ok {
+				c.queue.moveToFront(i)
+			}
+		}
+		c.reads.buffered.Add(-int64(len(stripe.keys)))
+		clear(stripe.keys)
+		stripe.keys = stripe.keys[:0]
+		stripe.size.Store(0)
+		stripe.mu.Unlock()
+	}
+}
+
+// publish stores the value of the key to the read index, if it is enabled. The caller must hold the mutex.
+func (c *lruCache[K, V]) publish(key K, value V) {
+	if c.reads != nil {
+		c.reads.index.Store(key, &readEntry[V]{value})
+	}
+}
+
+// unpublish removes the key from the read index, if it is enabled. The caller must hold the mutex.
+func (c *lruCache[K, V]) unpublish(key K) {
+	if c.reads != nil {
+		c.reads.index.Delete(key)
+	}
+}
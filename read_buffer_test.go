@@ -0,0 +1,118 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBuffers(t *testing.T) {
+	t.Run("get", func(t *testing.T) {
+		c := NewCache(3, WithReadBuffers[string, int]())
+
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+
+		c.Set("key1", 100)
+		c.Set("key1", 101)
+		v, ok := c.Get("key1")
+		require.True(t, ok)
+		require.Equal(t, 101, v)
+
+		c.Delete("key1")
+		_, ok = c.Get("key1")
+		require.False(t, ok)
+
+		c.Set("key2", 200)
+		c.Clear()
+		_, ok = c.Get("key2")
+		require.False(t, ok)
+	})
+
+	// The conformance suite is not run, since the accesses recorded to the different stripes are reordered.
+	t.Run("buffered reads are applied by the next lock holder", func(t *testing.T) {
+		c := NewCache(3, WithReadBuffers[string, int]())
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Set("key3", 300)
+
+		c.Get("key1")
+		c.Set("key4", 400) // key1 is promoted before the eviction, so key2 is evicted.
+
+		_, ok := c.Get("key2")
+		require.False(t, ok)
+		_, ok = c.Get("key1")
+		require.True(t, ok)
+	})
+
+	t.Run("evicted keys are not resurrected by the buffered reads", func(t *testing.T) {
+		c := NewCache(2, WithReadBuffers[string, int]())
+		c.Set("key1", 100)
+		c.Get("key1")
+		c.Delete("key1")
+
+		c.Set("key2", 200)
+		c.Set("key3", 300)
+		require.Equal(t, map[string]int{"key2": 200, "key3": 300}, c.GetMany([]string{"key1", "key2", "key3"}))
+	})
+
+	t.Run("full buffers are drained by readers", func(t *testing.T) {
+		c := NewCache(2, WithReadBuffers[string, int]()).(*lruCache[string, int])
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+
+		for range len(c.reads.stripes) * readBufferSize {
+			c.Get("key1")
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		require.Equal(t, "key1", c.queue.entry(c.queue.Front()).key)
+	})
+
+	t.Run("busy stripes are skipped", func(t *testing.T) {
+		c := NewCache(2, WithReadBuffers[string, int]()).(*lruCache[string, int])
+		c.Set("key1", 100)
+		c.Get("key1")
+		require.Equal(t, int64(1), c.reads.buffered.Load())
+
+		for i := range c.reads.stripes {
+			c.reads.stripes[i].mu.Lock()
+		}
+		c.Set("key2", 200) // Not blocked by the stripes.
+		for i := range c.reads.stripes {
+			c.reads.stripes[i].mu.Unlock()
+		}
+		require.Equal(t, int64(1), c.reads.buffered.Load())
+
+		c.Len()
+		require.Zero(t, c.reads.buffered.Load())
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		c := NewCache(100, WithReadBuffers[string, int]())
+		wg := &sync.WaitGroup{}
+		wg.Add(8)
+
+		for g := range 8 {
+			go func() {
+				defer wg.Done()
+				for i := range 10_000 {
+					key := strconv.Itoa(i % 200)
+					if g%4 == 0 {
+						c.Set(key, i)
+						continue
+					}
+					if v, ok := c.Get(key); ok {
+						require.Equal(t, key, strconv.Itoa(v%200))
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.NotEmpty(t, c.GetMany([]string{"0", "199"}))
+	})
+}
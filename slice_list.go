@@ -0,0 +1,53 @@
+package lru
+
+// NewSliceList returns a new list with 0 length which stores its items in contiguous slices
+// of the given capacity instead of allocating them one by one. Removed items are kept in a free list
+// and reused by the next insertions, so they must not be used after the removal.
+// The slice-backed list cuts the number of heap objects the GC has to track for large long-lived lists.
+// If the capacity is less than 1, it returns nil.
+func NewSliceList[V any](capacity int) List[V] {
+	if capacity < 1 {
+		return nil
+	}
+
+	return &list[V]{pool: &nodePool[V]{chunkSize: capacity}}
+}
+
+// nodePool allocates the list items from contiguous chunks and recycles the removed ones.
+// A new chunk is allocated only when the current one is exhausted and there are no free items,
+// the previous chunks are kept alive by the items pointing into them.
+type nodePool[V any] struct {
+	chunkSize int
+	chunk     []ListItem[V]
+	free      []*ListItem[V]
+}
+
+// get returns a detached item holding the value v.
+func (p *nodePool[V]) get(v V) *ListItem[V] {
+	var i *ListItem[V]
+
+	switch {
+	case len(p.free) > 0:
+		i = p.free[len(p.free)-1]
+		p.free[len(p.free)-1] = nil
+		p.free = p.free[:len(p.free)-1]
+	default:
+		if len(p.chunk) == 0 {
+			p.chunk = make([]ListItem[V], p.chunkSize)
+		}
+		i = &p.chunk[0]
+		p.chunk = p.chunk[1:]
+	}
+
+	i.Value = v
+
+	return i
+}
+
+// put returns the detached item to the free list. The value is zeroed, so the item does not keep it alive.
+func (p *nodePool[V]) put(i *ListItem[V]) {
+	var zeroVal V
+	i.Value = zeroVal
+
+	p.free = append(p.free, i)
+}
@@ -0,0 +1,73 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSliceList(t *testing.T) {
+	t.Run("incorrect capacity", func(t *testing.T) {
+		require.Nil(t, NewSliceList[any](0))
+		require.Nil(t, NewSliceList[any](-1))
+	})
+
+	t.Run("complex behavior", func(t *testing.T) {
+		suite.Run(t, &BehaviorTestSuite{newList: func() List[any] { return NewSliceList[any](4) }})
+	})
+
+	t.Run("items are allocated in chunks", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(10, func() {
+			l := NewSliceList[int](64)
+			for i := range 64 {
+				l.PushBack(i)
+			}
+		})
+
+		// The list itself, its pool and a single chunk.
+		require.LessOrEqual(t, allocs, 3.0)
+	})
+
+	t.Run("removed items are reused", func(t *testing.T) {
+		l := NewSliceList[*int](2)
+		value := new(int)
+		first := l.PushBack(value)
+		l.PushBack(value)
+
+		require.Same(t, value, l.Remove(first))
+		require.Nil(t, first.Value, "the value is kept alive by the free item")
+
+		require.Same(t, first, l.PushFront(value))
+		require.Equal(t, 2, l.Len())
+
+		allocs := testing.AllocsPerRun(100, func() {
+			l.Remove(l.PushBack(value))
+		})
+		require.Zero(t, allocs)
+	})
+
+	t.Run("the list grows beyond the capacity", func(t *testing.T) {
+		l := NewSliceList[int](2)
+		for i := range 5 {
+			l.PushBack(i)
+		}
+
+		elems := make([]int, 0, l.Len())
+		for i := l.Front(); i != nil; i = i.Next {
+			elems = append(elems, i.Value)
+		}
+		require.Equal(t, []int{0, 1, 2, 3, 4}, elems)
+	})
+
+	t.Run("init recycles the items", func(t *testing.T) {
+		l := NewSliceList[int](2)
+		first := l.PushBack(1)
+		second := l.PushBack(2)
+		l.Init()
+
+		third := l.PushBack(3)
+		fourth := l.PushBack(4)
+		require.ElementsMatch(t, []*ListItem[int]{first, second}, []*ListItem[int]{third, fourth})
+	})
+}
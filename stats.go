@@ -0,0 +1,134 @@
+package lru
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cache statistics.
+type Stats struct {
+	// Hits is the number of the lookups which found the key.
+	Hits uint64
+	// Misses is the number of the lookups which did not find the key.
+	Misses uint64
+	// GhostHits is the number of the misses of the keys recently evicted due to the capacity,
+	// i.e. the lookups which would have hit with more capacity. It is counted if the ghost entries are tracked,
+	// see WithGhostEntries.
+	GhostHits uint64
+	// Evictions is the number of the items evicted due to the capacity.
+	Evictions uint64
+	// MemoryEvictions is the number of the items evicted by the MemoryWatcher.
+	MemoryEvictions uint64
+	// Len is the number of the items in the cache.
+	Len int
+	// Capacity is the current capacity of the cache.
+	Capacity int
+}
+
+// StatsReporter is implemented by the caches reporting their statistics, such as the ones created by NewCache.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// counters keeps the statistics of the cache. They are updated atomically, since the lookups
+// of the read-buffered caches do not hold the mutex.
+type counters struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	ghostHits atomic.Uint64
+	evictions atomic.Uint64
+	// memoryEvictions is updated under the mutex, but it is atomic along with the others for consistency.
+	memoryEvictions atomic.Uint64
+}
+
+// WithGhostEntries makes the cache remember up to size keys recently evicted due to its capacity, without their
+// values. Misses of these keys are counted as the ghost hits in the stats, see Stats.GhostHits.
+func WithGhostEntries[K comparable, V any](size int) Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		if size > 0 {
+			c.ghosts = newGhostList[K](size)
+		}
+	}
+}
+
+// Stats returns a snapshot of the cache statistics.
+func (c *lruCache[K, V]) Stats() Stats {
+	c.lock()
+	defer c.unlock()
+
+	return Stats{
+		Hits:            c.stats.hits.Load(),
+		Misses:          c.stats.misses.Load(),
+		GhostHits:       c.stats.ghostHits.Load(),
+		Evictions:       c.stats.evictions.Load(),
+		MemoryEvictions: c.stats.memoryEvictions.Load(),
+		Len:             c.queue.Len(),
+		Capacity:        c.capacity,
+	}
+}
+
+// recordLookup counts the lookup of the key. The key is requested for the ghost lookup of a miss only.
+// The caller must hold the mutex.
+func (c *lruCache[K, V]) recordLookup(key func() K, found bool) {
+	if found {
+		c.stats.hits.Add(1)
+		return
+	}
+
+	c.stats.misses.Add(1)
+	if c.ghosts != nil && c.ghosts.contains(key()) {
+		c.stats.ghostHits.Add(1)
+	}
+}
+
+// unghost forgets the key added to the cache again. The caller must hold the mutex.
+func (c *lruCache[K, V]) unghost(key K) {
+	if c.ghosts != nil {
+		c.ghosts.remove(key)
+	}
+}
+
+// ghostList is a bounded LRU list of the keys evicted from the cache. It is not safe for concurrent use.
+type ghostList[K comparable] struct {
+	size  int
+	queue List[K]
+	items map[K]*ListItem[K]
+}
+
+func newGhostList[K comparable](size int) *ghostList[K] {
+	return &ghostList[K]{
+		size:  size,
+		queue: NewList[K](),
+		items: make(map[K]*ListItem[K], size),
+	}
+}
+
+// add remembers the evicted key, forgetting the oldest one if the list is full.
+func (g *ghostList[K]) add(key K) {
+	if elem, ok := g.items[key]; ok {
+		g.queue.MoveToFront(elem)
+		return
+	}
+
+	g.items[key] = g.queue.PushFront(key)
+	if g.queue.Len() > g.size {
+		delete(g.items, g.queue.Remove(g.queue.Back()))
+	}
+}
+
+// remove forgets the key, e.g. when it is added to the cache again.
+func (g *ghostList[K]) remove(key K) {
+	if elem, ok := g.items[key]; ok {
+		delete(g.items, key)
+		g.queue.Remove(elem)
+	}
+}
+
+// contains reports whether the key was evicted recently.
+func (g *ghostList[K]) contains(key K) bool {
+	_, ok := g.items[key]
+	return ok
+}
+
+// clear forgets all the keys.
+func (g *ghostList[K]) clear() {
+	g.queue.Init()
+	clear(g.items)
+}
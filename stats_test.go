@@ -0,0 +1,69 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("counters", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Get("key1")
+		c.Get("key3")
+		c.GetMany([]string{"key1", "key2", "key4"})
+		c.Set("key3", 300) // Evicts key1.
+		c.Delete("key3")
+		GetBytes(c, []byte("key2"))
+
+		require.Equal(t, Stats{Hits: 4, Misses: 2, Evictions: 1, Len: 1, Capacity: 2}, c.(StatsReporter).Stats())
+	})
+
+	t.Run("ghost hits", func(t *testing.T) {
+		c := NewCache(2, WithGhostEntries[string, int](2))
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Set("key3", 300) // Evicts key1.
+		c.Set("key4", 400) // Evicts key2.
+		c.Set("key5", 500) // Evicts key3, key1 is forgotten.
+
+		c.Get("key1")
+		c.Get("key2")
+		c.Get("key3")
+		c.Get("key6")
+		require.Equal(t, uint64(2), c.(StatsReporter).Stats().GhostHits)
+
+		// The keys set again are not ghosts anymore.
+		c.Set("key2", 201)
+		c.Delete("key2")
+		c.Get("key2")
+		require.Equal(t, uint64(2), c.(StatsReporter).Stats().GhostHits)
+
+		c.Clear()
+		c.Get("key3")
+		require.Equal(t, Stats{Misses: 6, GhostHits: 2, Evictions: 4, Capacity: 2}, c.(StatsReporter).Stats())
+	})
+
+	t.Run("resize evictions are ghosts", func(t *testing.T) {
+		c := NewCache(3, WithGhostEntries[string, int](3))
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Resize(1)
+
+		c.Get("key1")
+		require.Equal(t, Stats{Misses: 1, GhostHits: 1, Evictions: 1, Len: 1, Capacity: 1}, c.(StatsReporter).Stats())
+	})
+
+	t.Run("read-buffered cache", func(t *testing.T) {
+		c := NewCache(1, WithReadBuffers[string, int](), WithGhostEntries[string, int](1))
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Get("key1")
+		c.Get("key2")
+		c.Get("key3")
+
+		require.Equal(t, Stats{Hits: 1, Misses: 2, GhostHits: 1, Evictions: 1, Len: 1, Capacity: 1}, c.(StatsReporter).Stats())
+	})
+}
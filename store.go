@@ -0,0 +1,398 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by the methods of a closed cache.
+var ErrClosed = errors.New("cache is closed")
+
+// Store is a backing store of the cache, e.g. a database.
+type Store[K comparable, V any] interface {
+	// Load returns the value for the key and its presence in the store.
+	Load(key K) (V, bool, error)
+	// Store saves the value for the key.
+	Store(key K, value V) error
+	// Delete removes the key from the store. Deleting a missing key is not an error.
+	Delete(key K) error
+}
+
+// WriteMode defines how the StoreCache propagates the changes to the Store.
+type WriteMode int
+
+const (
+	// WriteThrough writes the changes to the store synchronously before applying them to the cache.
+	WriteThrough WriteMode = iota
+	// WriteBehind applies the changes to the cache and queues them to be written to the store in the background.
+	WriteBehind
+)
+
+// StoreConfig configures the StoreCache.
+type StoreConfig struct {
+	// Mode is the write mode of the cache.
+	Mode WriteMode
+	// QueueSize is the maximum number of distinct keys waiting to be written in the WriteBehind mode.
+	// Writers are blocked while the queue is full. Defaults to 1024.
+	QueueSize int
+	// BatchSize is the maximum number of keys written in a single round in the WriteBehind mode. Defaults to 64.
+	BatchSize int
+	// FlushInterval is the time to wait for the batch to fill up before writing it in the WriteBehind mode.
+	// Zero means the queued changes are written as soon as possible.
+	FlushInterval time.Duration
+	// MaxRetries is the number of additional attempts for a failed write in the WriteBehind mode.
+	MaxRetries int
+	// RetryBackoff is the delay between the write attempts in the WriteBehind mode.
+	RetryBackoff time.Duration
+	// OnError is called for the write errors in the WriteBehind mode after all the attempts have failed. Optional.
+	OnError func(error)
+}
+
+const (
+	defaultQueueSize = 1024
+	defaultBatchSize = 64
+)
+
+// writeOp is a change waiting to be written to the store. Consequent changes of the same key are coalesced into it.
+type writeOp[V any] struct {
+	value   V
+	deleted bool
+}
+
+// StoreCache is an LRU cache backed by a Store. Missing keys are loaded from the store,
+// the changes are written to it either synchronously or in the background. It is safe for concurrent use.
+type StoreCache[K comparable, V any] struct {
+	cache Cache[K, V]
+	store Store[K, V]
+	cfg   StoreConfig
+
+	// writeMu serializes the writes in the WriteThrough mode, so the cache and the store apply them in the same order.
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	notFull  *sync.Cond
+	queue    []K
+	queued   map[K]*writeOp[V]
+	inflight map[K]*writeOp[V]
+	closed   bool
+	notify   chan struct{}
+	closing  chan struct{}
+	done     chan struct{}
+	closeErr error
+}
+
+// NewStoreCache returns a new StoreCache with the given capacity, store and configuration.
+// Options are applied to the underlying cache, its eviction callbacks must not call the StoreCache methods.
+// In the WriteBehind mode the cache starts a background writer, so it must be closed with the Close method.
+func NewStoreCache[K comparable, V any](
+	capacity int,
+	store Store[K, V],
+	cfg StoreConfig,
+	opts ...Option[K, V],
+) (*StoreCache[K, V], error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	s := &StoreCache[K, V]{
+		cache:    NewCache(capacity, opts...),
+		store:    store,
+		cfg:      cfg,
+		queued:   make(map[K]*writeOp[V]),
+		inflight: make(map[K]*writeOp[V]),
+		notify:   make(chan struct{}, 1),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.notFull = sync.NewCond(&s.mu)
+
+	if cfg.Mode == WriteBehind {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+
+	return s, nil
+}
+
+// Get returns a value for a key from the cache. Missing keys are loaded from the store and cached,
+// the changes which are not written to the store yet take precedence over it.
+// Returns zero value and false if the key is present neither in the cache nor in the store.
+func (s *StoreCache[K, V]) Get(key K) (V, bool, error) {
+	var zeroVal V
+
+	// The key is loaded through the cache, so the loaded value is not cached if the key is set or deleted
+	// while it is being loaded.
+	res, err := s.cache.GetManyOrLoad(context.Background(), []K{key}, s.load)
+	if err != nil {
+		return zeroVal, false, err
+	}
+
+	v, ok := res[key]
+
+	return v, ok, nil
+}
+
+// load is the batch loader of the cache. The pending changes of the keys take precedence over the store.
+func (s *StoreCache[K, V]) load(_ context.Context, keys []K) (map[K]V, error) {
+	res := make(map[K]V, len(keys))
+
+	for _, key := range keys {
+		if op, ok := s.pending(key); ok {
+			if !op.deleted {
+				res[key] = op.value
+			}
+			continue
+		}
+
+		v, ok, err := s.store.Load(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res[key] = v
+		}
+	}
+
+	return res, nil
+}
+
+// Set adds a key-value pair to the cache and writes it to the store according to the write mode.
+// In the WriteThrough mode the cache is not updated if the store fails.
+// In the WriteBehind mode it blocks while the write queue is full.
+// Returns true if the key was already present in the cache, false otherwise.
+func (s *StoreCache[K, V]) Set(key K, value V) (bool, error) {
+	if s.cfg.Mode == WriteBehind {
+		return s.enqueue(key, writeOp[V]{value: value}, func() bool { return s.cache.Set(key, value) })
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.isClosed() {
+		return false, ErrClosed
+	}
+	if err := s.store.Store(key, value); err != nil {
+		return false, err
+	}
+
+	return s.cache.Set(key, value), nil
+}
+
+// Delete removes the key from the cache and from the store according to the write mode.
+// Returns true if the key was present in the cache, false otherwise.
+func (s *StoreCache[K, V]) Delete(key K) (bool, error) {
+	if s.cfg.Mode == WriteBehind {
+		return s.enqueue(key, writeOp[V]{deleted: true}, func() bool { return s.cache.Delete(key) })
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.isClosed() {
+		return false, ErrClosed
+	}
+	if err := s.store.Delete(key); err != nil {
+		return false, err
+	}
+
+	return s.cache.Delete(key), nil
+}
+
+// Clear removes all stored items from the cache. The store and the write queue are not affected.
+func (s *StoreCache[K, V]) Clear() {
+	s.cache.Clear()
+}
+
+// Close flushes the write queue and stops the background writer. Returns the errors of the final flush.
+// Subsequent calls return ErrClosed.
+func (s *StoreCache[K, V]) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	close(s.closing)
+	// Waking up the writers blocked on the full queue.
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+
+	return s.closeErr
+}
+
+// isClosed reports whether the cache is closed.
+func (s *StoreCache[K, V]) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// pending returns the latest change of the key which is not written to the store yet.
+func (s *StoreCache[K, V]) pending(key K) (writeOp[V], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if op, ok := s.queued[key]; ok {
+		return *op, true
+	}
+	if op, ok := s.inflight[key]; ok {
+		return *op, true
+	}
+
+	return writeOp[V]{}, false
+}
+
+// enqueue applies the change to the cache and queues it for the background writer.
+// The change is coalesced with the queued one for the same key, if any.
+func (s *StoreCache[K, V]) enqueue(key K, op writeOp[V], apply func() bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false, ErrClosed
+	}
+
+	if queued, ok := s.queued[key]; ok {
+		*queued = op
+		return apply(), nil
+	}
+
+	for len(s.queue) >= s.cfg.QueueSize && !s.closed {
+		s.notFull.Wait()
+	}
+	if s.closed {
+		return false, ErrClosed
+	}
+
+	s.queue = append(s.queue, key)
+	s.queued[key] = &op
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+
+	return apply(), nil
+}
+
+// run is the loop of the background writer. On close it flushes the queue and exits.
+func (s *StoreCache[K, V]) run() {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-s.notify:
+			s.waitForBatch()
+		case <-s.closing:
+		}
+
+		select {
+		case <-s.closing:
+			var errs []error
+			s.flush(func(err error) { errs = append(errs, err) })
+			s.closeErr = errors.Join(errs...)
+			return
+		default:
+			s.flush(s.cfg.OnError)
+		}
+	}
+}
+
+// waitForBatch waits for the batch to fill up within the flush interval.
+func (s *StoreCache[K, V]) waitForBatch() {
+	if s.cfg.FlushInterval <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	full := len(s.queue) >= s.cfg.BatchSize
+	s.mu.Unlock()
+	if full {
+		return
+	}
+
+	timer := time.NewTimer(s.cfg.FlushInterval)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-s.closing:
+	}
+}
+
+// flush writes the queued changes to the store in batches until the queue is empty.
+func (s *StoreCache[K, V]) flush(onError func(error)) {
+	for {
+		batch := s.takeBatch()
+		if len(batch) == 0 {
+			return
+		}
+
+		for _, key := range batch {
+			if err := s.write(key, s.inflight[key]); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+
+		s.mu.Lock()
+		clear(s.inflight)
+		s.mu.Unlock()
+	}
+}
+
+// takeBatch moves up to BatchSize keys from the queue to the in-flight set.
+func (s *StoreCache[K, V]) takeBatch() []K {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := min(len(s.queue), s.cfg.BatchSize)
+	batch := make([]K, n)
+	copy(batch, s.queue)
+	s.queue = append(s.queue[:0], s.queue[n:]...)
+
+	for _, key := range batch {
+		s.inflight[key] = s.queued[key]
+		delete(s.queued, key)
+	}
+
+	s.notFull.Broadcast()
+
+	return batch
+}
+
+// write applies the change to the store, retrying it on failures.
+// The in-flight changes are not modified by the writers, so op is read without the mutex.
+func (s *StoreCache[K, V]) write(key K, op *writeOp[V]) error {
+	var err error
+
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 && s.cfg.RetryBackoff > 0 {
+			time.Sleep(s.cfg.RetryBackoff)
+		}
+
+		if op.deleted {
+			err = s.store.Delete(key)
+		} else {
+			err = s.store.Store(key, op.value)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("write key %v: %w", key, err)
+}
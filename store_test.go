@@ -0,0 +1,320 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is an in-memory Store recording the number of writes.
+type memStore struct {
+	mu      sync.Mutex
+	data    map[string]int
+	writes  int
+	failing int // The number of the next writes to fail.
+	block   chan struct{}
+	// afterLoad is called after a value is read by Load, before it is returned.
+	afterLoad func()
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: make(map[string]int)}
+}
+
+func (m *memStore) Load(key string) (int, bool, error) {
+	m.mu.Lock()
+	v, ok := m.data[key]
+	m.mu.Unlock()
+
+	if m.afterLoad != nil {
+		m.afterLoad()
+	}
+	return v, ok, nil
+}
+
+func (m *memStore) Store(key string, value int) error {
+	if m.block != nil {
+		<-m.block
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writes++
+	if m.failing > 0 {
+		m.failing--
+		return errBackend
+	}
+
+	m.data[key] = value
+	return nil
+}
+
+func (m *memStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writes++
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memStore) snapshot() (map[string]int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data := make(map[string]int, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return data, m.writes
+}
+
+func TestStoreCacheWriteThrough(t *testing.T) {
+	t.Run("incorrect capacity", func(t *testing.T) {
+		c, err := NewStoreCache[string, int](0, newMemStore(), StoreConfig{})
+		require.ErrorIs(t, err, ErrInvalidCapacity)
+		require.Nil(t, c)
+	})
+
+	t.Run("set and delete", func(t *testing.T) {
+		store := newMemStore()
+		c, err := NewStoreCache[string, int](1, store, StoreConfig{Mode: WriteThrough})
+		require.NoError(t, err)
+
+		existed, err := c.Set("key1", 100)
+		require.NoError(t, err)
+		require.False(t, existed)
+
+		data, _ := store.snapshot()
+		require.Equal(t, map[string]int{"key1": 100}, data)
+
+		existed, err = c.Delete("key1")
+		require.NoError(t, err)
+		require.True(t, existed)
+
+		data, _ = store.snapshot()
+		require.Empty(t, data)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("missing keys are loaded", func(t *testing.T) {
+		store := newMemStore()
+		store.data["key1"] = 100
+		c, err := NewStoreCache[string, int](1, store, StoreConfig{})
+		require.NoError(t, err)
+
+		v, ok, err := c.Get("key1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+
+		_, ok, err = c.Get("key2")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		store := newMemStore()
+		store.failing = 1
+		c, err := NewStoreCache[string, int](1, store, StoreConfig{})
+		require.NoError(t, err)
+
+		_, err = c.Set("key1", 100)
+		require.ErrorIs(t, err, errBackend)
+
+		_, ok, err := c.Get("key1")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("delete during the load", func(t *testing.T) {
+		store := newMemStore()
+		store.data["key1"] = 100
+		loaded, release := make(chan struct{}), make(chan struct{})
+		store.afterLoad = func() {
+			close(loaded)
+			<-release
+		}
+
+		c, err := NewStoreCache[string, int](1, store, StoreConfig{})
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Get("key1")
+		}()
+		<-loaded
+
+		_, err = c.Delete("key1")
+		require.NoError(t, err)
+		close(release)
+		<-done
+
+		store.afterLoad = nil
+		_, ok, err := c.Get("key1")
+		require.NoError(t, err)
+		require.False(t, ok, "the deleted key is not cached again")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		c, err := NewStoreCache[string, int](1, newMemStore(), StoreConfig{})
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+		require.ErrorIs(t, c.Close(), ErrClosed)
+
+		_, err = c.Set("key1", 100)
+		require.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestStoreCacheWriteBehind(t *testing.T) {
+	t.Run("close flushes the queue", func(t *testing.T) {
+		store := newMemStore()
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Delete("key2")
+
+		require.NoError(t, c.Close())
+		data, _ := store.snapshot()
+		require.Equal(t, map[string]int{"key1": 100}, data)
+
+		_, err = c.Set("key3", 300)
+		require.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("coalescing", func(t *testing.T) {
+		store := newMemStore()
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		for i := range 100 {
+			c.Set("key1", i)
+		}
+
+		require.NoError(t, c.Close())
+		data, writes := store.snapshot()
+		require.Equal(t, map[string]int{"key1": 99}, data)
+		require.Equal(t, 1, writes)
+	})
+
+	t.Run("background writes", func(t *testing.T) {
+		store := newMemStore()
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, BatchSize: 2})
+		require.NoError(t, err)
+		defer c.Close()
+
+		for i, key := range []string{"key1", "key2", "key3"} {
+			c.Set(key, i)
+		}
+
+		require.Eventually(t, func() bool {
+			data, _ := store.snapshot()
+			return len(data) == 3
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("pending changes take precedence over the store", func(t *testing.T) {
+		store := newMemStore()
+		store.data["key1"] = 100
+		store.data["key2"] = 200
+		c, err := NewStoreCache[string, int](1, store, StoreConfig{Mode: WriteBehind, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		c.Set("key1", 101)
+		c.Delete("key2")
+		c.Set("key3", 300) // key1 is evicted from the cache, but it is not written yet.
+
+		v, ok, err := c.Get("key1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 101, v)
+
+		_, ok, err = c.Get("key2")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		store := newMemStore()
+		store.failing = 2
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, MaxRetries: 2})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		require.NoError(t, c.Close())
+
+		data, writes := store.snapshot()
+		require.Equal(t, map[string]int{"key1": 100}, data)
+		require.Equal(t, 3, writes)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		store := newMemStore()
+		store.failing = 2
+		errs := make(chan error, 1)
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{
+			Mode:       WriteBehind,
+			MaxRetries: 1,
+			OnError:    func(err error) { errs <- err },
+		})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		require.ErrorIs(t, <-errs, errBackend)
+
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("errors of the final flush", func(t *testing.T) {
+		store := newMemStore()
+		store.failing = 1
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, FlushInterval: time.Hour})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		require.ErrorIs(t, c.Close(), errBackend)
+	})
+
+	t.Run("bounded queue", func(t *testing.T) {
+		store := newMemStore()
+		store.block = make(chan struct{})
+		c, err := NewStoreCache[string, int](10, store, StoreConfig{Mode: WriteBehind, QueueSize: 1, BatchSize: 1})
+		require.NoError(t, err)
+
+		c.Set("key1", 100) // Taken by the writer which is blocked by the store.
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return len(c.queue) == 0
+		}, time.Second, time.Millisecond)
+		c.Set("key2", 200) // Fills up the queue.
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key3", 300)
+		}()
+
+		select {
+		case <-done:
+			require.Fail(t, "set is not blocked by the full queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(store.block)
+		<-done
+		require.NoError(t, c.Close())
+
+		data, _ := store.snapshot()
+		require.Equal(t, map[string]int{"key1": 100, "key2": 200, "key3": 300}, data)
+	})
+}
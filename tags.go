@@ -0,0 +1,77 @@
+package lru
+
+import "strings"
+
+// SetWithTags adds a key-value pair to the cache the same way as Set does and associates the given tags
+// with the key. The tags replace the ones previously associated with the key.
+// Other methods updating the value keep the tags of the entry.
+// Returns true if the key was already present in the cache, false otherwise.
+func (c *lruCache[K, V]) SetWithTags(key K, value V, tags ...string) bool {
+	c.lock()
+	defer c.unlock()
+
+	existed := c.set(key, value)
+
+	// The new item is always at the front of the queue, so it is never evicted by set.
+	item := c.queue.entry(c.items[key])
+	c.untag(item)
+	c.tag(item, tags)
+
+	return existed
+}
+
+// InvalidateTag removes all the keys associated with the tag from the cache.
+// Returns the number of removed keys.
+func (c *lruCache[K, V]) InvalidateTag(tag string) int {
+	c.lock()
+	defer c.unlock()
+
+	keys := c.tags[tag]
+	n := len(keys)
+
+	for key := range keys {
+		c.remove(c.items[key], EvictionReasonDeleted)
+	}
+
+	return n
+}
+
+// DeletePrefix removes all the keys with the given prefix from the cache.
+// Returns the number of removed keys.
+func DeletePrefix[K ~string, V any](c Cache[K, V], prefix string) int {
+	return c.DeleteFunc(func(key K, _ V) bool {
+		return strings.HasPrefix(string(key), prefix)
+	})
+}
+
+// tag associates the untagged item with the tags in the tag index. The caller must hold the mutex.
+func (c *lruCache[K, V]) tag(item *cacheEntry[K, V], tags []string) {
+	for _, t := range tags {
+		keys, ok := c.tags[t]
+		if !ok {
+			keys = make(map[K]struct{})
+			c.tags[t] = keys
+		}
+
+		// Skipping duplicate tags.
+		if _, ok := keys[item.key]; ok {
+			continue
+		}
+
+		keys[item.key] = struct{}{}
+		item.tags = append(item.tags, t)
+	}
+}
+
+// untag removes the item from the tag index. The caller must hold the mutex.
+func (c *lruCache[K, V]) untag(item *cacheEntry[K, V]) {
+	for _, t := range item.tags {
+		delete(c.tags[t], item.key)
+
+		if len(c.tags[t]) == 0 {
+			delete(c.tags, t)
+		}
+	}
+
+	item.tags = nil
+}
@@ -0,0 +1,116 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelete(t *testing.T) {
+	c := NewCache[string, int](3)
+	c.Set("key1", 100)
+	c.Set("key2", 200)
+
+	require.True(t, c.Delete("key1"))
+	require.False(t, c.Delete("key1"))
+	require.False(t, c.Delete("key3"))
+
+	_, ok := c.Get("key1")
+	require.False(t, ok)
+	v, ok := c.Get("key2")
+	require.True(t, ok)
+	require.Equal(t, 200, v)
+}
+
+func TestTags(t *testing.T) {
+	t.Run("invalidate tag", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		c.SetWithTags("profile", 100, "user1")
+		c.SetWithTags("avatar", 200, "user1", "images")
+		c.SetWithTags("logo", 300, "images")
+		c.Set("untagged", 400)
+
+		require.Equal(t, 2, c.InvalidateTag("user1"))
+		require.Equal(t, 0, c.InvalidateTag("user1"))
+		require.Equal(t, 0, c.InvalidateTag("unknown"))
+
+		require.Equal(t, map[string]int{"logo": 300, "untagged": 400},
+			c.GetMany([]string{"profile", "avatar", "logo", "untagged"}))
+
+		require.Equal(t, 1, c.InvalidateTag("images"))
+		_, ok := c.Get("logo")
+		require.False(t, ok)
+	})
+
+	t.Run("tags are replaced by SetWithTags", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		require.False(t, c.SetWithTags("key1", 100, "tag1"))
+		require.True(t, c.SetWithTags("key1", 200, "tag2", "tag2"))
+
+		require.Equal(t, 0, c.InvalidateTag("tag1"))
+		require.Equal(t, 1, c.InvalidateTag("tag2"))
+	})
+
+	t.Run("tags are kept by other updates", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		c.SetWithTags("key1", 100, "tag1")
+		c.Set("key1", 200)
+		c.Replace("key1", 300)
+
+		require.Equal(t, 1, c.InvalidateTag("tag1"))
+	})
+
+	t.Run("eviction keeps the index consistent", func(t *testing.T) {
+		c := NewCache[string, int](2)
+		c.SetWithTags("key1", 100, "tag1")
+		c.SetWithTags("key2", 200, "tag1")
+		c.SetWithTags("key3", 300, "tag2") // key1 is evicted.
+
+		cache := c.(*lruCache[string, int])
+		require.Equal(t, map[string]map[string]struct{}{
+			"tag1": {"key2": {}},
+			"tag2": {"key3": {}},
+		}, cache.tags)
+
+		c.Delete("key2")
+		require.Equal(t, 0, c.InvalidateTag("tag1"))
+		require.NotContains(t, cache.tags, "tag1")
+
+		// The tags of the evicted key are not restored by a plain Set.
+		c.Set("key1", 101)
+		require.Equal(t, 1, c.InvalidateTag("tag2"))
+		v, ok := c.Get("key1")
+		require.True(t, ok)
+		require.Equal(t, 101, v)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		c := NewCache[string, int](10)
+		c.SetWithTags("key1", 100, "tag1")
+		c.Clear()
+		c.Set("key1", 200)
+
+		require.Equal(t, 0, c.InvalidateTag("tag1"))
+		_, ok := c.Get("key1")
+		require.True(t, ok)
+	})
+}
+
+func TestDeletePrefix(t *testing.T) {
+	type userKey string
+
+	c := NewCache[userKey, int](10)
+	c.Set("user:1:profile", 100)
+	c.Set("user:1:avatar", 200)
+	c.Set("user:2:profile", 300)
+
+	require.Equal(t, 2, DeletePrefix(c, "user:1:"))
+	require.Equal(t, 0, DeletePrefix(c, "user:1:"))
+
+	_, ok := c.Get("user:1:profile")
+	require.False(t, ok)
+	_, ok = c.Get("user:2:profile")
+	require.True(t, ok)
+
+	require.Equal(t, 1, DeletePrefix(c, ""))
+}
@@ -0,0 +1,202 @@
+package lru
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrInvalidCapacity is returned by the constructors for capacities less than 1.
+var ErrInvalidCapacity = errors.New("capacity must be positive")
+
+// ErrInvalidMaxBytes is returned by NewTieredCache for byte budgets less than 1.
+var ErrInvalidMaxBytes = errors.New("disk byte budget must be positive")
+
+// DiskConfig describes the on-disk level of the TieredCache.
+type DiskConfig[V any] struct {
+	// Dir is the directory for the cache files. Every cache keeps its files in its own subdirectory
+	// with the lru- prefix, so several caches may share the directory. The subdirectory is removed by Close.
+	Dir string
+	// MaxBytes is the byte budget of the stored values. It must be positive.
+	MaxBytes int64
+	// Codec converts the values to bytes and back. GobCodec is used if it is nil.
+	Codec Codec[V]
+	// OnError is called for the disk errors which cannot be returned to the caller,
+	// e.g. when items evicted from the memory are written to the disk. Optional.
+	OnError func(error)
+}
+
+// TieredCache is a two-level cache. Items evicted from the in-memory LRU cache are written to an on-disk LRU store
+// and are promoted back to the memory on a hit. It is safe for concurrent use.
+type TieredCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	front   Cache[K, V]
+	disk    *diskStore[K]
+	codec   Codec[V]
+	onError func(error)
+	closed  bool
+	onEvict func(key K, value V, reason EvictionReason)
+	// evicted keeps the items passed to the eviction callback of the options after the mutex is released.
+	evicted []evictedEntry[K, V]
+}
+
+// NewTieredCache returns a new TieredCache keeping up to capacity items in the memory
+// and the items evicted from it on the disk. Options are applied to the in-memory cache,
+// its eviction callback is also called for the items moved to the disk. The callback is called after
+// the TieredCache mutex is released, so it is safe to call the TieredCache methods from it.
+func NewTieredCache[K comparable, V any](
+	capacity int,
+	disk DiskConfig[V],
+	opts ...Option[K, V],
+) (*TieredCache[K, V], error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+	if disk.MaxBytes < 1 {
+		return nil, ErrInvalidMaxBytes
+	}
+
+	store, err := newDiskStore[K](disk.Dir, disk.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TieredCache[K, V]{
+		disk:    store,
+		codec:   disk.Codec,
+		onError: disk.OnError,
+	}
+	if t.codec == nil {
+		t.codec = GobCodec[V]{}
+	}
+
+	t.front = NewCache(capacity, append(opts, t.spillOption())...)
+
+	return t, nil
+}
+
+// spillOption returns an option which writes the items evicted from the memory due to its capacity to the disk.
+// The eviction callback is called by the goroutine holding the TieredCache mutex, so it is not acquired here.
+// The eviction callback set by the other options is deferred until the mutex is released.
+func (t *TieredCache[K, V]) spillOption() Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		next := c.onEvict
+		c.onEvict = func(key K, value V, reason EvictionReason) {
+			if reason == EvictionReasonCapacity {
+				t.spill(key, value)
+			}
+			if next != nil {
+				t.evicted = append(t.evicted, evictedEntry[K, V]{key, value, reason})
+			}
+		}
+		t.onEvict = next
+	}
+}
+
+// lock acquires the mutex.
+func (t *TieredCache[K, V]) lock() {
+	t.mu.Lock()
+}
+
+// unlock releases the mutex, then calls the eviction callback for the items evicted while it was held.
+func (t *TieredCache[K, V]) unlock() {
+	evicted := t.evicted
+	t.evicted = nil
+	t.mu.Unlock()
+
+	for _, e := range evicted {
+		t.onEvict(e.key, e.value, e.reason)
+	}
+}
+
+// spill writes the item to the disk. The caller must hold the mutex.
+func (t *TieredCache[K, V]) spill(key K, value V) {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		t.handleError(err)
+		return
+	}
+
+	t.handleError(t.disk.put(key, data))
+}
+
+// handleError passes a non-nil error to the error handler if it is set.
+func (t *TieredCache[K, V]) handleError(err error) {
+	if err != nil && t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// Set adds a key-value pair to the in-memory cache, dropping the stale value from the disk.
+// Returns true if the key was already present in any of the levels, false otherwise.
+func (t *TieredCache[K, V]) Set(key K, value V) bool {
+	t.lock()
+	defer t.unlock()
+
+	onDisk := t.disk.contains(key)
+	t.handleError(t.disk.remove(key))
+
+	return t.front.Set(key, value) || onDisk
+}
+
+// Get returns a value for a key if it exists in any of the levels. Items found on the disk
+// are moved back to the memory. Otherwise, returns zero value and false.
+func (t *TieredCache[K, V]) Get(key K) (V, bool) {
+	var zeroVal V
+
+	t.lock()
+	defer t.unlock()
+
+	if v, ok := t.front.Get(key); ok {
+		return v, true
+	}
+
+	data, ok, err := t.disk.take(key)
+	if err != nil || !ok {
+		t.handleError(err)
+		return zeroVal, false
+	}
+
+	v, err := t.codec.Unmarshal(data)
+	if err != nil {
+		t.handleError(err)
+		return zeroVal, false
+	}
+
+	t.front.Set(key, v)
+
+	return v, true
+}
+
+// Delete removes the key from both levels. Returns true if the key was present in any of them, false otherwise.
+func (t *TieredCache[K, V]) Delete(key K) bool {
+	t.lock()
+	defer t.unlock()
+
+	onDisk := t.disk.contains(key)
+	t.handleError(t.disk.remove(key))
+
+	return t.front.Delete(key) || onDisk
+}
+
+// Clear removes all stored items from both levels.
+func (t *TieredCache[K, V]) Clear() {
+	t.lock()
+	defer t.unlock()
+
+	t.front.Clear()
+	t.handleError(t.disk.clear())
+}
+
+// Close removes all stored items from both levels along with the directory of the disk level.
+// The cache must not be used after that. Subsequent calls return ErrClosed.
+func (t *TieredCache[K, V]) Close() error {
+	t.lock()
+	defer t.unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+	t.closed = true
+
+	return errors.Join(t.front.Close(), t.disk.close())
+}
@@ -0,0 +1,194 @@
+package lru
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Averlex/lru/lrutest"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type failingCodec struct {
+	GobCodec[int]
+}
+
+func (failingCodec) Marshal(int) ([]byte, error) {
+	return nil, errBackend
+}
+
+func TestTieredCache(t *testing.T) {
+	t.Run("conformance", func(t *testing.T) {
+		// The eviction suite is not run, since the items evicted from the memory are kept on the disk.
+		helper := lrutest.CacheTestHelper{NewCache: func(capacity int) lrutest.Cache {
+			c, err := NewTieredCache[string, int](capacity, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1 << 20})
+			require.NoError(t, err)
+			return c
+		}}
+
+		t.Run("single element cache", func(t *testing.T) {
+			suite.Run(t, &lrutest.SingleItemCacheSuite{CacheTestHelper: helper})
+		})
+		t.Run("multi element cache", func(t *testing.T) {
+			suite.Run(t, &lrutest.MultiItemCacheSuite{CacheTestHelper: helper})
+		})
+	})
+
+	t.Run("incorrect capacity", func(t *testing.T) {
+		c, err := NewTieredCache[string, int](0, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1024})
+		require.ErrorIs(t, err, ErrInvalidCapacity)
+		require.Nil(t, c)
+	})
+
+	t.Run("incorrect byte budget", func(t *testing.T) {
+		for _, maxBytes := range []int64{0, -1} {
+			c, err := NewTieredCache[string, int](1, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: maxBytes})
+			require.ErrorIs(t, err, ErrInvalidMaxBytes)
+			require.Nil(t, c)
+		}
+	})
+
+	t.Run("evicted items are promoted back", func(t *testing.T) {
+		var evicted []string
+		c, err := NewTieredCache(2, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1024},
+			WithEvictionCallback(func(key string, _ int, _ EvictionReason) {
+				evicted = append(evicted, key)
+			}))
+		require.NoError(t, err)
+
+		require.False(t, c.Set("key1", 100))
+		require.False(t, c.Set("key2", 200))
+		require.False(t, c.Set("key3", 300)) // key1 is moved to the disk.
+		require.Equal(t, []string{"key1"}, evicted)
+		require.True(t, c.disk.contains("key1"))
+
+		v, ok := c.Get("key1") // key2 is moved to the disk.
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+		require.False(t, c.disk.contains("key1"))
+		require.True(t, c.disk.contains("key2"))
+
+		_, ok = c.Get("key4")
+		require.False(t, ok)
+	})
+
+	t.Run("eviction callback calling the cache", func(t *testing.T) {
+		var c *TieredCache[string, int]
+		var deleted []bool
+		c, err := NewTieredCache(1, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1024},
+			WithEvictionCallback(func(key string, _ int, _ EvictionReason) {
+				deleted = append(deleted, c.Delete(key))
+			}))
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key1", 100)
+			c.Set("key2", 200) // key1 is moved to the disk and deleted by the callback.
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.FailNow(t, "the eviction callback is deadlocked")
+		}
+		require.Equal(t, []bool{true}, deleted)
+		require.False(t, c.disk.contains("key1"))
+	})
+
+	t.Run("set and delete drop the disk copy", func(t *testing.T) {
+		c, err := NewTieredCache[string, int](1, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1024})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		require.True(t, c.Set("key1", 101)) // key2 is moved to the disk.
+
+		v, ok := c.Get("key1")
+		require.True(t, ok)
+		require.Equal(t, 101, v)
+
+		require.True(t, c.Delete("key2"))
+		require.False(t, c.Delete("key2"))
+		_, ok = c.Get("key2")
+		require.False(t, ok)
+	})
+
+	t.Run("disk byte budget", func(t *testing.T) {
+		c, err := NewTieredCache[int, []byte](1, DiskConfig[[]byte]{Dir: t.TempDir(), MaxBytes: 256})
+		require.NoError(t, err)
+
+		for i := range 10 {
+			c.Set(i, make([]byte, 100))
+		}
+		require.LessOrEqual(t, c.disk.used, int64(256))
+
+		_, ok := c.Get(0)
+		require.False(t, ok)
+		_, ok = c.Get(8)
+		require.True(t, ok)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		c, err := NewTieredCache[string, int](1, DiskConfig[int]{Dir: t.TempDir(), MaxBytes: 1024})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Clear()
+
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+		_, ok = c.Get("key2")
+		require.False(t, ok)
+	})
+
+	t.Run("caches sharing the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		a, err := NewTieredCache[string, string](1, DiskConfig[string]{Dir: dir, MaxBytes: 1024})
+		require.NoError(t, err)
+		a.Set("k1", "from-A")
+		a.Set("k2", "from-A") // k1 is spilled to the disk.
+
+		b, err := NewTieredCache[string, string](1, DiskConfig[string]{Dir: dir, MaxBytes: 1024})
+		require.NoError(t, err)
+		b.Set("other", "from-B")
+		b.Set("k2", "from-B") // other is spilled to the disk.
+
+		v, ok := a.Get("k1")
+		require.True(t, ok)
+		require.Equal(t, "from-A", v)
+		v, ok = b.Get("other")
+		require.True(t, ok)
+		require.Equal(t, "from-B", v)
+
+		require.NoError(t, a.Close())
+		require.ErrorIs(t, a.Close(), ErrClosed)
+		require.NoError(t, b.Close())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries, "the subdirectories are removed")
+	})
+
+	t.Run("errors are passed to the handler", func(t *testing.T) {
+		var errs []error
+		c, err := NewTieredCache[string, int](1, DiskConfig[int]{
+			Dir:      t.TempDir(),
+			MaxBytes: 1024,
+			Codec:    failingCodec{},
+			OnError:  func(err error) { errs = append(errs, err) },
+		})
+		require.NoError(t, err)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], errBackend)
+
+		_, ok := c.Get("key1")
+		require.False(t, ok)
+	})
+}
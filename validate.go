@@ -0,0 +1,105 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCorrupted is returned by the Validate methods if the internal structure is inconsistent.
+var ErrCorrupted = errors.New("structure is corrupted")
+
+// Validate walks the list forward and backward, checking that the items are linked to each other
+// in both directions, belong to the list, and their number matches the length of the list.
+// Returns an error wrapping ErrCorrupted for the first inconsistency found.
+func (l *list[V]) Validate() error {
+	if l.len < 0 {
+		return fmt.Errorf("%w: negative length %d", ErrCorrupted, l.len)
+	}
+	if (l.front == nil) != (l.back == nil) || (l.front == nil) != (l.len == 0) {
+		return fmt.Errorf("%w: front and back do not match length %d", ErrCorrupted, l.len)
+	}
+	if l.len == 0 {
+		return nil
+	}
+	if l.front.Prev != nil {
+		return fmt.Errorf("%w: front item has a previous item", ErrCorrupted)
+	}
+	if l.back.Next != nil {
+		return fmt.Errorf("%w: back item has a next item", ErrCorrupted)
+	}
+
+	// The walks are bounded by the length, so a cycle is reported instead of looping forever.
+	n := 0
+	last := l.front
+	for i := l.front; i != nil; i = i.Next {
+		if n == l.len {
+			return fmt.Errorf("%w: more than %d items walking forward", ErrCorrupted, l.len)
+		}
+		if !l.owns(i) {
+			return fmt.Errorf("%w: item %d does not belong to the list", ErrCorrupted, n)
+		}
+		if i.Next != nil && i.Next.Prev != i {
+			return fmt.Errorf("%w: item %d is not linked back by the next item", ErrCorrupted, n)
+		}
+		last = i
+		n++
+	}
+	if n != l.len {
+		return fmt.Errorf("%w: %d items walking forward, length is %d", ErrCorrupted, n, l.len)
+	}
+	if last != l.back {
+		return fmt.Errorf("%w: last item is not the back one", ErrCorrupted)
+	}
+
+	n = 0
+	for i := l.back; i != nil; i = i.Prev {
+		if n == l.len {
+			return fmt.Errorf("%w: more than %d items walking backward", ErrCorrupted, l.len)
+		}
+		n++
+	}
+	if n != l.len {
+		return fmt.Errorf("%w: %d items walking backward, length is %d", ErrCorrupted, n, l.len)
+	}
+
+	return nil
+}
+
+// Validator is implemented by the structures which can check their internal consistency,
+// such as the lists and the caches created by NewCache. It is meant for the tests and debugging.
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks the queue of the cache the same way as List.Validate does, also checks that the map and the queue
+// have the same size, every map entry points to a queue item holding its key, and the tag index refers
+// to the cached keys only. Returns an error wrapping ErrCorrupted for the first inconsistency found.
+func (c *lruCache[K, V]) Validate() error {
+	c.lock()
+	defer c.unlock()
+
+	if err := c.queue.Validate(); err != nil {
+		return fmt.Errorf("queue: %w", err)
+	}
+
+	if len(c.items) != c.queue.Len() {
+		return fmt.Errorf("%w: %d keys in the map, %d items in the queue", ErrCorrupted, len(c.items), c.queue.Len())
+	}
+
+	for i := c.queue.Front(); i != nilNode; i = c.queue.next(i) {
+		key := c.queue.entry(i).key
+		if j, ok := c.items[key]; !ok || j != i {
+			return fmt.Errorf("%w: map entry of key %v does not point to its queue item", ErrCorrupted, key)
+		}
+	}
+
+	for tag, keys := range c.tags {
+		for key := range keys {
+			if _, ok := c.items[key]; !ok {
+				return fmt.Errorf("%w: tag %q refers to missing key %v", ErrCorrupted, tag, key)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListValidate(t *testing.T) {
+	lists := map[string]func() List[int]{
+		"list":       NewList[int],
+		"slice list": func() List[int] { return NewSliceList[int](2) },
+	}
+
+	for name, newList := range lists {
+		t.Run(name, func(t *testing.T) {
+			l := newList()
+			require.NoError(t, l.Validate())
+
+			first := l.PushBack(1)
+			require.NoError(t, l.Validate())
+
+			l.PushBack(2)
+			l.PushFront(0)
+			l.MoveToBack(first)
+			l.InsertAfter(3, first)
+			l.Remove(l.Front())
+			require.NoError(t, l.Validate())
+
+			l.Init()
+			require.NoError(t, l.Validate())
+		})
+	}
+
+	corruptions := []struct {
+		name    string
+		corrupt func(l *list[int])
+	}{
+		{"length", func(l *list[int]) { l.len++ }},
+		{"negative length", func(l *list[int]) { l.len = -1 }},
+		{"missing back", func(l *list[int]) { l.back = nil }},
+		{"front with previous item", func(l *list[int]) { l.front.Prev = l.back }},
+		{"back with next item", func(l *list[int]) { l.back.Next = l.front }},
+		{"broken backward link", func(l *list[int]) { l.front.Next.Prev = nil }},
+		{"cycle", func(l *list[int]) { l.front.Next.Next.Next = l.front.Next }},
+		{"foreign item", func(l *list[int]) { l.front.Next.owner = nil }},
+		{"wrong back", func(l *list[int]) { l.back = l.front.Next }},
+	}
+
+	for _, tc := range corruptions {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewList[int]().(*list[int])
+			for i := range 4 {
+				l.PushBack(i)
+			}
+
+			tc.corrupt(l)
+			require.ErrorIs(t, l.Validate(), ErrCorrupted)
+		})
+	}
+}
+
+func TestCacheValidate(t *testing.T) {
+	newCache := func() *lruCache[string, int] {
+		c := NewCache[string, int](3).(*lruCache[string, int])
+		c.SetWithTags("key1", 100, "tag")
+		c.Set("key2", 200)
+		c.Set("key3", 300)
+		return c
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		c := newCache()
+		require.NoError(t, c.Validate())
+
+		c.Set("key4", 400)
+		c.Get("key2")
+		c.Delete("key3")
+		c.Resize(1)
+		require.NoError(t, c.Validate())
+
+		c.Clear()
+		require.NoError(t, c.Validate())
+	})
+
+	corruptions := []struct {
+		name    string
+		corrupt func(c *lruCache[string, int])
+	}{
+		{"queue", func(c *lruCache[string, int]) { c.queue.node(c.queue.Front()).next = nilNode }},
+		{"missing map entry", func(c *lruCache[string, int]) { delete(c.items, "key2") }},
+		{"map entry pointing to another item", func(c *lruCache[string, int]) { c.items["key2"] = c.items["key3"] }},
+		{"key of the item", func(c *lruCache[string, int]) { c.queue.entry(c.items["key2"]).key = "key4" }},
+		{"tag of a missing key", func(c *lruCache[string, int]) { c.tags["tag"]["key4"] = struct{}{} }},
+	}
+
+	for _, tc := range corruptions {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCache()
+			tc.corrupt(c)
+			require.ErrorIs(t, c.Validate(), ErrCorrupted)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package lru
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is the type of a cache mutation reported by Watch.
+// There is no expiration event, since the cache has no TTL: the entries are removed only by the deletions,
+// the evictions and Clear.
+type EventType int
+
+const (
+	// EventSet means a new key was added to the cache.
+	EventSet EventType = iota
+	// EventUpdate means the value of a cached key was replaced.
+	EventUpdate
+	// EventDelete means the key was removed explicitly, e.g. by Delete, DeleteFunc or InvalidateTag.
+	EventDelete
+	// EventEvict means the key was evicted by the cache, the reason of the eviction is reported along with it.
+	EventEvict
+	// EventClear means all the keys were removed by Clear or Close. It is reported once, without a key.
+	EventClear
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	case EventEvict:
+		return "evict"
+	case EventClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a cache mutation reported by Watch.
+type Event[K comparable, V any] struct {
+	Type EventType
+	Key  K
+	// Value is the new value for EventSet and EventUpdate, the removed value for EventDelete and EventEvict.
+	Value V
+	// Reason is the reason of the eviction for EventEvict.
+	Reason EvictionReason
+	// Dropped is the number of the events dropped for the subscriber before this one due to the buffer overflow.
+	Dropped uint64
+}
+
+// OverflowPolicy defines what happens to the events of a subscriber whose buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops the events which do not fit into the buffer.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock blocks the delivery of the events until the subscriber reads them or the timeout expires,
+	// then the event is dropped. The cache mutex is not held during the delivery, but the cache methods
+	// mutating the cache wait for the delivery of the previous events to keep their order. The blocking is always
+	// bounded, since a subscriber mutating the cache with its buffer full would otherwise wait for itself.
+	OverflowBlock
+)
+
+// WatchConfig configures the subscriptions made by Watch.
+type WatchConfig struct {
+	// BufferSize is the number of the events buffered for a subscriber. Defaults to 64.
+	BufferSize int
+	// Overflow is the policy for the events which do not fit into the buffer.
+	Overflow OverflowPolicy
+	// BlockTimeout is the limit of the blocking for OverflowBlock. Defaults to 1 second.
+	BlockTimeout time.Duration
+}
+
+const (
+	defaultWatchBufferSize   = 64
+	defaultWatchBlockTimeout = time.Second
+)
+
+// WithWatchConfig sets the configuration of the subscriptions made by Watch.
+func WithWatchConfig[K comparable, V any](cfg WatchConfig) Option[K, V] {
+	return func(c *lruCache[K, V]) {
+		c.watchCfg = cfg
+	}
+}
+
+// watchHub delivers the events to the subscribers in the order of the mutations.
+type watchHub[K comparable, V any] struct {
+	cfg WatchConfig
+	// active is the number of the subscribers, the events are not collected without them.
+	active atomic.Int32
+	// issued is the ticket of the next batch of events. It is modified under the cache mutex.
+	issued uint64
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// next is the ticket of the batch to be delivered next.
+	next uint64
+	subs map[*watcher[K, V]]struct{}
+}
+
+// watcher is a single subscription.
+type watcher[K comparable, V any] struct {
+	ch      chan Event[K, V]
+	done    <-chan struct{}
+	dropped uint64
+}
+
+// Watcher is implemented by the caches reporting their mutations, such as the ones created by NewCache.
+type Watcher[K comparable, V any] interface {
+	Watch(ctx context.Context) <-chan Event[K, V]
+}
+
+// Watch subscribes to the mutations of the cache. The events are sent to the returned channel in the order
+// of the mutations until the context is done, then the channel is closed. Reads do not produce events.
+// The buffering and the overflow of the subscription are configured by WithWatchConfig.
+func (c *lruCache[K, V]) Watch(ctx context.Context) <-chan Event[K, V] {
+	c.lock()
+	if c.watch == nil {
+		cfg := c.watchCfg
+		if cfg.BufferSize < 1 {
+			cfg.BufferSize = defaultWatchBufferSize
+		}
+		if cfg.BlockTimeout <= 0 {
+			cfg.BlockTimeout = defaultWatchBlockTimeout
+		}
+
+		c.watch = &watchHub[K, V]{cfg: cfg, subs: make(map[*watcher[K, V]]struct{})}
+		c.watch.cond = sync.NewCond(&c.watch.mu)
+	}
+	hub := c.watch
+	c.unlock()
+
+	w := &watcher[K, V]{ch: make(chan Event[K, V], hub.cfg.BufferSize), done: ctx.Done()}
+
+	hub.mu.Lock()
+	hub.subs[w] = struct{}{}
+	hub.active.Add(1)
+	hub.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		hub.mu.Lock()
+		delete(hub.subs, w)
+		hub.active.Add(-1)
+		close(w.ch)
+		hub.mu.Unlock()
+	}()
+
+	return w.ch
+}
+
+// notify schedules the event for the subscribers, if there are any. The caller must hold the mutex.
+func (c *lruCache[K, V]) notify(e Event[K, V]) {
+	if c.watch != nil && c.watch.active.Load() > 0 {
+		c.events = append(c.events, e)
+	}
+}
+
+// ticket returns the ticket for the delivery of the scheduled events. The caller must hold the mutex.
+func (h *watchHub[K, V]) ticket() uint64 {
+	t := h.issued
+	h.issued++
+
+	return t
+}
+
+// deliver sends the events to the subscribers after the batches with the preceding tickets are delivered.
+// It is called after the cache mutex is released. The ticket is passed on even if the delivery panics,
+// so the following batches are not blocked forever.
+func (h *watchHub[K, V]) deliver(ticket uint64, events []Event[K, V]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for h.next != ticket {
+		h.cond.Wait()
+	}
+	defer func() {
+		h.next++
+		h.cond.Broadcast()
+	}()
+
+	for w := range h.subs {
+		for _, e := range events {
+			h.send(w, e)
+		}
+	}
+}
+
+// send delivers the event to the subscriber according to the overflow policy. The caller must hold the mutex.
+func (h *watchHub[K, V]) send(w *watcher[K, V], e Event[K, V]) {
+	e.Dropped = w.dropped
+
+	select {
+	case w.ch <- e:
+		w.dropped = 0
+		return
+	default:
+	}
+
+	if h.cfg.Overflow != OverflowBlock {
+		w.dropped++
+		return
+	}
+
+	timer := time.NewTimer(h.cfg.BlockTimeout)
+	defer timer.Stop()
+
+	select {
+	case w.ch <- e:
+		w.dropped = 0
+	case <-timer.C:
+		w.dropped++
+	case <-w.done:
+	}
+}
@@ -0,0 +1,351 @@
+package lru
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receive reads n events from the channel failing the test if they are not delivered in time.
+func receive[K comparable, V any](t *testing.T, ch <-chan Event[K, V], n int) []Event[K, V] {
+	t.Helper()
+
+	events := make([]Event[K, V], 0, n)
+	for range n {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		case <-time.After(time.Second):
+			require.FailNow(t, "event is not delivered", "received %v", events)
+		}
+	}
+
+	return events
+}
+
+// watch subscribes to the mutations of the cache through the Watcher interface.
+func watch[K comparable, V any](ctx context.Context, c Cache[K, V]) <-chan Event[K, V] {
+	return c.(Watcher[K, V]).Watch(ctx)
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("event types", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache[string, int](2)
+		ch := watch(ctx, c)
+
+		c.Set("key1", 100)
+		c.Set("key1", 101)
+		c.Set("key2", 200)
+		c.Set("key3", 300)
+		c.Delete("key2")
+		c.Get("key3")
+		c.Clear()
+
+		require.Equal(t, []Event[string, int]{
+			{Type: EventSet, Key: "key1", Value: 100},
+			{Type: EventUpdate, Key: "key1", Value: 101},
+			{Type: EventSet, Key: "key2", Value: 200},
+			{Type: EventEvict, Key: "key1", Value: 101, Reason: EvictionReasonCapacity},
+			{Type: EventSet, Key: "key3", Value: 300},
+			{Type: EventDelete, Key: "key2", Value: 200},
+			{Type: EventClear, Reason: EvictionReasonCleared},
+		}, receive(t, ch, 7))
+
+		select {
+		case e := <-ch:
+			require.Fail(t, "unexpected event", "%v", e)
+		default:
+		}
+	})
+
+	t.Run("cancellation closes the channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := NewCache[string, int](2)
+		ch := watch(ctx, c)
+
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := <-ch
+			return !ok
+		}, time.Second, time.Millisecond)
+
+		c.Set("key1", 100)
+		require.Nil(t, c.(*lruCache[string, int]).events)
+	})
+
+	t.Run("several subscribers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache[string, int](2)
+		first, second := watch(ctx, c), watch(ctx, c)
+
+		c.Set("key1", 100)
+
+		want := []Event[string, int]{{Type: EventSet, Key: "key1", Value: 100}}
+		require.Equal(t, want, receive(t, first, 1))
+		require.Equal(t, want, receive(t, second, 1))
+	})
+
+	t.Run("overflow drops the events", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(10, WithWatchConfig[string, int](WatchConfig{BufferSize: 2}))
+		ch := watch(ctx, c)
+
+		for i, key := range []string{"key1", "key2", "key3", "key4"} {
+			c.Set(key, i)
+		}
+		require.Equal(t, []Event[string, int]{
+			{Type: EventSet, Key: "key1", Value: 0},
+			{Type: EventSet, Key: "key2", Value: 1},
+		}, receive(t, ch, 2))
+
+		c.Set("key5", 4)
+		require.Equal(t, []Event[string, int]{{Type: EventSet, Key: "key5", Value: 4, Dropped: 2}}, receive(t, ch, 1))
+	})
+
+	t.Run("overflow blocks the writers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(10, WithWatchConfig[string, int](WatchConfig{BufferSize: 1, Overflow: OverflowBlock}))
+		ch := watch(ctx, c)
+
+		c.Set("key1", 100)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key2", 200)
+		}()
+
+		select {
+		case <-done:
+			require.Fail(t, "set is not blocked by the full buffer")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// Readers are not blocked by the delivery.
+		v, ok := c.Get("key2")
+		require.True(t, ok)
+		require.Equal(t, 200, v)
+
+		require.Len(t, receive(t, ch, 2), 2)
+		<-done
+	})
+
+	t.Run("blocking times out", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(10, WithWatchConfig[string, int](WatchConfig{
+			BufferSize:   1,
+			Overflow:     OverflowBlock,
+			BlockTimeout: time.Millisecond,
+		}))
+		ch := watch(ctx, c)
+
+		c.Set("key1", 100)
+		c.Set("key2", 200)
+		c.Set("key3", 300)
+
+		require.Equal(t, []Event[string, int]{{Type: EventSet, Key: "key1", Value: 100}}, receive(t, ch, 1))
+
+		c.Set("key4", 400)
+		require.Equal(t, []Event[string, int]{{Type: EventSet, Key: "key4", Value: 400, Dropped: 2}}, receive(t, ch, 1))
+	})
+
+	t.Run("cancellation unblocks the writers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := NewCache(10, WithWatchConfig[string, int](WatchConfig{BufferSize: 1, Overflow: OverflowBlock}))
+		watch(ctx, c)
+
+		c.Set("key1", 100)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key2", 200)
+		}()
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.Fail(t, "set is blocked after the cancellation")
+		}
+	})
+
+	t.Run("eviction callback mutating the cache", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var c Cache[string, int]
+		c = NewCache(1, WithEvictionCallback(func(key string, _ int, _ EvictionReason) {
+			if key == "key1" {
+				c.Set("key3", 300)
+			}
+		}))
+		ch := watch(ctx, c)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key1", 100)
+			c.Set("key2", 200)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.FailNow(t, "set is blocked by the callback")
+		}
+		require.Len(t, receive(t, ch, 5), 5)
+	})
+
+	t.Run("panicking eviction callback", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(1, WithEvictionCallback(func(key string, _ int, _ EvictionReason) {
+			if key == "key1" {
+				panic("callback")
+			}
+		}))
+		ch := watch(ctx, c)
+
+		c.Set("key1", 100)
+		require.Panics(t, func() { c.Set("key2", 200) })
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key3", 300)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.FailNow(t, "set is blocked after the panic")
+		}
+		require.Len(t, receive(t, ch, 5), 5)
+	})
+
+	t.Run("panicking value close", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(2, WithValueClosing[string, io.Closer](nil))
+		ch := watch(ctx, c)
+
+		c.Set("key1", panickingCloser{})
+		require.Panics(t, func() { c.Close() })
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key2", panickingCloser{})
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			require.FailNow(t, "set is blocked after the panic")
+		}
+		require.Len(t, receive(t, ch, 3), 3)
+	})
+
+	t.Run("subscriber mutating the cache with the full buffer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := NewCache(10, WithWatchConfig[string, int](WatchConfig{BufferSize: 1, Overflow: OverflowBlock}))
+		ch := watch(ctx, c)
+
+		c.Set("key1", 100)
+
+		// The subscriber does not read while it is writing, the blocking ends with the default timeout.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Set("key2", 200)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the subscriber is blocked by itself")
+		}
+		require.Equal(t, []Event[string, int]{{Type: EventSet, Key: "key1", Value: 100}}, receive(t, ch, 1))
+	})
+
+	t.Run("concurrent writers keep the order", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		const writers, writes = 4, 200
+
+		c := NewCache(1, WithWatchConfig[int, int](WatchConfig{BufferSize: 16, Overflow: OverflowBlock}))
+		ch := watch(ctx, c)
+
+		wg := sync.WaitGroup{}
+		for w := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range writes {
+					c.Set(w, i)
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			c.Set(writers, 0) // The last event.
+		}()
+
+		// With the capacity of 1 every insert evicts the previous key, which must have been inserted before.
+		current, cached := -1, false
+		for current != writers {
+			e := receive(t, ch, 1)[0]
+			switch e.Type {
+			case EventSet:
+				require.False(t, cached, "the previous key is not evicted")
+				current, cached = e.Key, true
+			case EventUpdate:
+				require.True(t, cached)
+				require.Equal(t, current, e.Key)
+			case EventEvict:
+				require.True(t, cached)
+				require.Equal(t, current, e.Key)
+				cached = false
+			default:
+				require.Fail(t, "unexpected event", "%v", e)
+			}
+		}
+	})
+}
+
+func TestEventTypeString(t *testing.T) {
+	require.Equal(t, "set", EventSet.String())
+	require.Equal(t, "update", EventUpdate.String())
+	require.Equal(t, "delete", EventDelete.String())
+	require.Equal(t, "evict", EventEvict.String())
+	require.Equal(t, "clear", EventClear.String())
+	require.Equal(t, "unknown", EventType(-1).String())
+}
+
+// panickingCloser is a value panicking on close.
+type panickingCloser struct{}
+
+func (panickingCloser) Close() error {
+	panic("close")
+}